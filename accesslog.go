@@ -0,0 +1,57 @@
+package talentpitchtools
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger is the minimal logger used by AccessLogMiddleware (*log.Logger satisfies it)
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+// AccessLogEntry is the structured access log line emitted for each request
+//
+// Deprecated: the access log is emitted by LoggerMiddleware, whose records carry
+// these fields plus the slog level and message
+type AccessLogEntry struct {
+	Time      string  `json:"time"`
+	Method    string  `json:"method"`
+	Path      string  `json:"path"`
+	Status    int     `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	ClientIP  string  `json:"client_ip"`
+	UserID    string  `json:"user_id,omitempty"`
+	RequestID string  `json:"request_id,omitempty"`
+}
+
+/*****************************************************************
+* Function Name: AccessLogMiddleware
+* Description: Middleware that logs each request as a JSON line
+* through a Printf logger. It is LoggerMiddleware with a JSON slog
+* handler writing to logger, so both emit the same fields
+* If logger is nil, the standard logger is used
+*
+* Deprecated: use LoggerMiddleware
+*****************************************************************/
+func AccessLogMiddleware(logger Logger) gin.HandlerFunc {
+	if logger == nil {
+		logger = log.Default()
+	}
+	handler := slog.NewJSONHandler(printfWriter{logger: logger}, nil)
+	return LoggerMiddleware(LogConfig{Logger: slog.New(handler)})
+}
+
+// printfWriter writes each slog record as one Printf call
+type printfWriter struct {
+	logger Logger
+}
+
+// Write implements io.Writer
+func (w printfWriter) Write(p []byte) (int, error) {
+	w.logger.Printf("%s", bytes.TrimRight(p, "\n"))
+	return len(p), nil
+}
@@ -0,0 +1,161 @@
+package talentpitchtools
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultAPIKeyHeader is the header the API key is read from by default
+const DefaultAPIKeyHeader = "X-API-Key"
+
+// APIKeyPrincipal is the integration authenticated by an API key
+type APIKeyPrincipal struct {
+	// Name identifies the integration (e.g., "billing-service")
+	Name string
+	// Scopes are the permissions granted to the key
+	Scopes []string
+}
+
+// HasScope checks if the principal was granted the scope
+func (p *APIKeyPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyValidator validates API keys, e.g., against a database of hashed keys
+type KeyValidator interface {
+	// ValidateKey returns the principal of the key, or ok=false if it is not valid
+	// Implementations should compare keys in constant time (see StaticKeyValidator)
+	ValidateKey(key string) (principal *APIKeyPrincipal, ok bool, err error)
+}
+
+// StaticKeyValidator is a KeyValidator for a fixed set of keys, mapping each key to
+// its principal. Keys are compared in constant time
+type StaticKeyValidator map[string]APIKeyPrincipal
+
+// ValidateKey compares the key with every configured key in constant time, so the
+// response time does not reveal how much of a key matched
+func (v StaticKeyValidator) ValidateKey(key string) (*APIKeyPrincipal, bool, error) {
+	keyHash := sha256.Sum256([]byte(key))
+
+	var found *APIKeyPrincipal
+	for candidate, principal := range v {
+		candidateHash := sha256.Sum256([]byte(candidate))
+		if subtle.ConstantTimeCompare(keyHash[:], candidateHash[:]) == 1 {
+			principal := principal
+			found = &principal
+		}
+	}
+	return found, found != nil, nil
+}
+
+// APIKeyConfig holds configuration for APIKeyMiddleware
+type APIKeyConfig struct {
+	// Validator validates the keys and returns their principal (required)
+	Validator KeyValidator
+	// HeaderName is the header the key is read from (defaults to "X-API-Key")
+	HeaderName string
+}
+
+// headerName returns the configured header name or the default
+func (cfg APIKeyConfig) headerName() string {
+	if cfg.HeaderName == "" {
+		return DefaultAPIKeyHeader
+	}
+	return cfg.HeaderName
+}
+
+// API key error codes returned by the API key middlewares
+const (
+	// APIKeyErrorMissing means no API key was sent
+	APIKeyErrorMissing = "api_key_missing"
+	// APIKeyErrorInvalid means the API key is not valid
+	APIKeyErrorInvalid = "api_key_invalid"
+	// APIKeyErrorCheckFailed means the key validator failed
+	APIKeyErrorCheckFailed = "api_key_check_failed"
+)
+
+/*****************************************************************
+* Function Name: APIKeyMiddleware
+* Description: Middleware for server-to-server integrations that send
+* a static API key instead of a JWT. Reads the key from the configured
+* header (X-API-Key by default), validates it with the KeyValidator
+* and stores the principal in context
+* Errors: 401 api_key_missing/api_key_invalid, 503 api_key_check_failed
+* if the validator fails (fail closed)
+* CORS preflight requests (OPTIONS with Origin and
+* Access-Control-Request-Method) are let through without a key
+* Then use: talentpitchtools.GetAPIKeyPrincipal(c) to get the principal
+*****************************************************************/
+func APIKeyMiddleware(cfg APIKeyConfig) gin.HandlerFunc {
+	if cfg.Validator == nil {
+		panic("talentpitchtools: APIKeyConfig.Validator is required")
+	}
+	headerName := cfg.headerName()
+
+	return func(c *gin.Context) {
+		if isPreflightRequest(c) {
+			c.Next()
+			return
+		}
+
+		key := strings.TrimSpace(c.GetHeader(headerName))
+		if key == "" {
+			abortWithError(c, http.StatusUnauthorized, APIKeyErrorMissing, "API key is required")
+			return
+		}
+
+		principal, ok, err := cfg.Validator.ValidateKey(key)
+		if err != nil {
+			log.Printf("Error validating API key%s: %v", logRequestID(c), err)
+			abortWithError(c, http.StatusServiceUnavailable, APIKeyErrorCheckFailed, "Could not verify the API key")
+			return
+		}
+		if !ok || principal == nil {
+			abortWithError(c, http.StatusUnauthorized, APIKeyErrorInvalid, "API key is invalid")
+			return
+		}
+
+		c.Set("api_key_principal", principal)
+		c.Next()
+	}
+}
+
+/*****************************************************************
+* Function Name: JWTOrAPIKeyMiddleware
+* Description: Middleware for routes that accept either credential
+* type: requests carrying the API key header are authenticated with
+* APIKeyMiddleware, the rest with JWTMiddlewareWithConfig
+*****************************************************************/
+func JWTOrAPIKeyMiddleware(jwtCfg JWTConfig, keyCfg APIKeyConfig) gin.HandlerFunc {
+	jwtMiddleware := JWTMiddlewareWithConfig(jwtCfg)
+	apiKeyMiddleware := APIKeyMiddleware(keyCfg)
+	headerName := keyCfg.headerName()
+
+	return func(c *gin.Context) {
+		if c.GetHeader(headerName) != "" {
+			apiKeyMiddleware(c)
+			return
+		}
+		jwtMiddleware(c)
+	}
+}
+
+// GetAPIKeyPrincipal returns the principal stored in context by APIKeyMiddleware
+func GetAPIKeyPrincipal(c *gin.Context) (*APIKeyPrincipal, bool) {
+	value, ok := c.Get("api_key_principal")
+	if !ok {
+		return nil, false
+	}
+	principal, ok := value.(*APIKeyPrincipal)
+	return principal, ok && principal != nil
+}
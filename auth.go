@@ -0,0 +1,103 @@
+package talentpitchtools
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TalentPitchCode/talentpitch-tools-go/helpers"
+	"github.com/gin-gonic/gin"
+)
+
+// GetUser returns the claims of the authenticated user stored in context by the JWT
+// middlewares (the "user" key), or ok=false if none set one (e.g., the optional JWT
+// middleware without a token)
+func GetUser(c *gin.Context) (*helpers.CustomClaims, bool) {
+	value, ok := c.Get("user")
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*helpers.CustomClaims)
+	return claims, ok && claims != nil
+}
+
+// GetUserFromContext is GetUser, kept for existing callers
+func GetUserFromContext(c *gin.Context) (*helpers.CustomClaims, bool) {
+	return GetUser(c)
+}
+
+// MustGetUser returns the claims of the authenticated user. It panics if there is
+// none, so only use it on routes behind JWTMiddleware
+func MustGetUser(c *gin.Context) *helpers.CustomClaims {
+	user, ok := GetUser(c)
+	if !ok {
+		panic("talentpitchtools: no authenticated user in context, is JWTMiddleware registered?")
+	}
+	return user
+}
+
+// GetUserID returns the ID of the authenticated user (the "sub" claim) as a number
+// Returns ok=false if there is no user or the ID is not numeric
+func GetUserID(c *gin.Context) (uint, bool) {
+	user, ok := GetUser(c)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(user.ID, 10, strconv.IntSize)
+	if err != nil {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// GetProfileID returns the profile ID of the authenticated user
+// Returns ok=false if there is no user or the token has no profile
+func GetProfileID(c *gin.Context) (uint, bool) {
+	user, ok := GetUser(c)
+	if !ok || user.ProfileId == 0 {
+		return 0, false
+	}
+	return user.ProfileId, true
+}
+
+/*****************************************************************
+* Function Name: RequireRoles
+* Description: Middleware that only lets through users whose claims
+* include at least one of the roles. Must be registered after
+* JWTMiddleware; aborts with 401 if there is no authenticated user
+* and 403 if the user has none of the roles
+*****************************************************************/
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := GetUser(c)
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, "unauthorized", "Authentication is required")
+			return
+		}
+
+		for _, role := range roles {
+			if user.HasRole(role) {
+				c.Next()
+				return
+			}
+		}
+
+		abortWithError(c, http.StatusForbidden, "forbidden", "User does not have the required role")
+	}
+}
+
+// AuthErrorKey is the context key where the optional JWT middleware records why a
+// token was ignored: a helpers.ParseStatus string (expired, bad_signature,
+// malformed, not_yet_valid), AuthErrorWrongTokenType, or the rejections of the
+// JWTConfig checks (invalid_issuer, invalid_audience, revoked, user_inactive,
+// revocation_check_failed, user_status_check_failed)
+const AuthErrorKey = "auth_error"
+
+// AuthErrorWrongTokenType is the auth error of refresh tokens sent as access tokens
+const AuthErrorWrongTokenType = "wrong_type"
+
+// GetAuthError returns why the optional JWT middleware ignored the request token,
+// or "" if there was no token or it was valid. The request is never blocked, so
+// handlers and logging can report on token health (e.g., prompt a re-login)
+func GetAuthError(c *gin.Context) string {
+	return c.GetString(AuthErrorKey)
+}
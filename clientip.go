@@ -0,0 +1,113 @@
+package talentpitchtools
+
+import (
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WithTrustedProxyCIDRs makes the client IP middleware trust the forwarded headers
+// (X-Forwarded-For, X-Real-IP) only when the immediate peer is in one of the ranges
+// (e.g., Cloudflare's published egress CIDRs). Requests from other peers use the
+// direct remote address, so spoofed headers are ignored. Plain IPs are accepted too
+// Invalid entries are logged and skipped
+func WithTrustedProxyCIDRs(cidrs []string) SetupOption {
+	return func(opts *setupOptions) {
+		if cidrs == nil {
+			cidrs = []string{}
+		}
+		opts.trustedProxyCIDRs = cidrs
+	}
+}
+
+// getClientIPFromTrusted is getClientIP honoring the forwarded headers only when the
+// immediate peer is in the trusted ranges
+func getClientIPFromTrusted(c *gin.Context, trusted []*net.IPNet) string {
+	peer := remoteIP(c.Request.RemoteAddr)
+	if !ipInRanges(peer, trusted) {
+		if peer != "" {
+			return peer
+		}
+		return c.ClientIP()
+	}
+
+	if ip, ok := trustedForwardedClientIP(c, trusted); ok {
+		return ip
+	}
+	return peer
+}
+
+// trustedForwardedClientIP reads the client IP behind trusted proxies. Each proxy
+// appends the address it received the request from, so only the right end of
+// X-Forwarded-For is reliable: entries are walked from right to left, skipping the
+// trusted proxies, and the first untrusted hop is the client. Anything to its left
+// was sent by the client and is ignored. Falls back to X-Real-IP
+func trustedForwardedClientIP(c *gin.Context, trusted []*net.IPNet) (string, bool) {
+	if forwardedFor := c.GetHeader("X-Forwarded-For"); forwardedFor != "" {
+		hops := strings.Split(forwardedFor, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := parseHeaderIP(hops[i])
+			if ip == "" {
+				// A malformed hop breaks the chain of trust
+				break
+			}
+			if !ipInRanges(ip, trusted) {
+				return ip, true
+			}
+		}
+	}
+
+	if ip := parseHeaderIP(c.GetHeader("X-Real-IP")); ip != "" {
+		return ip, true
+	}
+	return "", false
+}
+
+// forwardedClientIP reads the client IP from X-Forwarded-For (first entry) or X-Real-IP
+// The first entry is set by the client itself, so it is only reliable when every
+// request comes through our proxies (see trustedForwardedClientIP)
+func forwardedClientIP(c *gin.Context) (string, bool) {
+	// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2"
+	// The first IP is the original client IP
+	if forwardedFor := c.GetHeader("X-Forwarded-For"); forwardedFor != "" {
+		first, _, _ := strings.Cut(forwardedFor, ",")
+		if ip := parseHeaderIP(first); ip != "" {
+			return ip, true
+		}
+	}
+
+	if ip := parseHeaderIP(c.GetHeader("X-Real-IP")); ip != "" {
+		return ip, true
+	}
+	return "", false
+}
+
+// parseHeaderIP parses an IP from a forwarded header, accepting IPv4 and IPv6 with or
+// without port, brackets and zone ("1.2.3.4:80", "[2001:db8::1]:443", "fe80::1%eth0")
+// Returns the canonical IP without port and zone, or "" if it is not an IP
+func parseHeaderIP(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+	if zoneStart := strings.IndexByte(value, '%'); zoneStart != -1 {
+		value = value[:zoneStart]
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return ""
+	}
+	return ip.String()
+}
+
+// remoteIP returns the IP of the immediate peer from the request RemoteAddr
+func remoteIP(remoteAddr string) string {
+	return parseHeaderIP(remoteAddr)
+}
@@ -0,0 +1,154 @@
+package talentpitchtools
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	defaultCORSMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+	defaultCORSHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"}
+)
+
+// CORSConfig holds configuration for CORSMiddleware
+type CORSConfig struct {
+	// AllowedOrigins are the origins allowed to call the API: exact origins
+	// ("https://app.talentpitch.co"), wildcard subdomains with or without scheme
+	// ("*.talentpitch.co", "https://*.talentpitch.co", not matching the bare domain)
+	// or "*" for any origin
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in preflight requests
+	// (defaults to GET, POST, PUT, PATCH, DELETE and OPTIONS)
+	AllowedMethods []string
+	// AllowedHeaders are the request headers allowed in preflight requests
+	// (defaults to Origin, Content-Type, Accept, Authorization and X-Request-ID)
+	AllowedHeaders []string
+	// ExposedHeaders are the response headers readable by the browser
+	// (e.g., X-Moderation-Code)
+	ExposedHeaders []string
+	// AllowCredentials allows cookies and Authorization headers. The matching origin
+	// is echoed back instead of "*", as browsers require
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache preflight responses (0 omits the header)
+	MaxAge time.Duration
+}
+
+// isPreflightRequest reports whether the request is a CORS preflight: an OPTIONS
+// request with both the Origin and Access-Control-Request-Method headers. Auth
+// middlewares only let these through, so a bare OPTIONS cannot skip authentication
+func isPreflightRequest(c *gin.Context) bool {
+	return c.Request.Method == http.MethodOptions &&
+		c.GetHeader("Origin") != "" &&
+		c.GetHeader("Access-Control-Request-Method") != ""
+}
+
+/*****************************************************************
+* Function Name: CORSMiddleware
+* Description: Middleware that handles CORS for the allowed origins
+* Preflight (OPTIONS) requests are answered with 204 without reaching
+* the handlers, or rejected with 403 cors_origin_not_allowed if the
+* origin is not allowed. Other requests from disallowed origins are
+* served without CORS headers, so the browser blocks the response
+* Register it before the JWT middleware
+*****************************************************************/
+func CORSMiddleware(cfg CORSConfig) gin.HandlerFunc {
+	methods := cfg.AllowedMethods
+	if methods == nil {
+		methods = defaultCORSMethods
+	}
+	headers := cfg.AllowedHeaders
+	if headers == nil {
+		headers = defaultCORSHeaders
+	}
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(cfg.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		preflight := isPreflightRequest(c)
+		c.Writer.Header().Add("Vary", "Origin")
+
+		allowAll, allowed := matchOrigin(origin, cfg.AllowedOrigins)
+		if !allowed {
+			if preflight {
+				abortWithError(c, http.StatusForbidden, "cors_origin_not_allowed", "Origin is not allowed")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAll && !cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", allowMethods)
+			c.Header("Access-Control-Allow-Headers", allowHeaders)
+			if cfg.MaxAge > 0 {
+				c.Header("Access-Control-Max-Age", maxAge)
+			}
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if exposeHeaders != "" {
+			c.Header("Access-Control-Expose-Headers", exposeHeaders)
+		}
+		c.Next()
+	}
+}
+
+// matchOrigin checks the origin against the allowed origins. allowAll is true when
+// it matched "*"
+func matchOrigin(origin string, allowedOrigins []string) (allowAll bool, allowed bool) {
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Host == "" {
+		return false, false
+	}
+	scheme := strings.ToLower(parsed.Scheme)
+	host := strings.ToLower(parsed.Host)
+
+	for _, pattern := range allowedOrigins {
+		pattern = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(pattern), "/"))
+		if pattern == "*" {
+			return true, true
+		}
+
+		patternHost := pattern
+		if patternScheme, rest, found := strings.Cut(pattern, "://"); found {
+			if patternScheme != scheme {
+				continue
+			}
+			patternHost = rest
+		}
+
+		if suffix, wildcard := strings.CutPrefix(patternHost, "*."); wildcard {
+			if strings.HasSuffix(host, "."+suffix) {
+				return false, true
+			}
+			continue
+		}
+		if host == patternHost {
+			return false, true
+		}
+	}
+	return false, false
+}
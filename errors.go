@@ -0,0 +1,39 @@
+package talentpitchtools
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse is the JSON body of every error returned by the middlewares
+type ErrorResponse struct {
+	// Code is a machine-readable error code (e.g., "unauthorized", "CONTENT_SPAM")
+	Code string `json:"error"`
+	// Message is a human-readable description of the error
+	Message string `json:"message"`
+	// RequestID is the ID of the request, if known
+	RequestID string `json:"request_id,omitempty"`
+	// Stack is the stack trace of a recovered panic, only when enabled in RecoveryConfig
+	Stack string `json:"stack,omitempty"`
+}
+
+// ErrorResponder writes the error response of a rejected request. Replace it at
+// startup to customize the error shape of all the middlewares (e.g., wrap it in an
+// envelope); the request is aborted after it returns
+var ErrorResponder = func(c *gin.Context, status int, response ErrorResponse) {
+	c.JSON(status, response)
+}
+
+// abortWithError rejects the request through ErrorResponder
+func abortWithError(c *gin.Context, status int, code string, message string) {
+	ErrorResponder(c, status, newErrorResponse(c, code, message))
+	c.Abort()
+}
+
+// newErrorResponse builds the error response, taking the request ID from context
+func newErrorResponse(c *gin.Context, code string, message string) ErrorResponse {
+	return ErrorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: GetRequestID(c),
+	}
+}
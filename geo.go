@@ -0,0 +1,48 @@
+package talentpitchtools
+
+import (
+	"log"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GeoResolver resolves an IP to its ISO 3166-1 alpha-2 country code (e.g., "CO"),
+// backed by MaxMind, an external service or similar
+type GeoResolver interface {
+	Country(ip net.IP) (string, error)
+}
+
+/*****************************************************************
+* Function Name: GeoMiddleware
+* Description: Middleware that resolves the client IP stored by
+* clientIPMiddleware to a country code and stores it in context
+* The country is stored even when the lookup fails (empty string),
+* so handlers can always branch on it. Register it after the
+* client IP middleware
+* Then use: c.GetString("client_country") to get the country
+*****************************************************************/
+func GeoMiddleware(db GeoResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		country := ""
+		ip := c.GetString("client_ip")
+		if ip == "" {
+			ip = getClientIP(c)
+		}
+
+		if parsed := net.ParseIP(ip); parsed == nil {
+			log.Printf("Could not resolve country of invalid client IP %q%s", ip, logRequestID(c))
+		} else if db != nil {
+			resolved, err := db.Country(parsed)
+			if err != nil {
+				log.Printf("Error resolving country of %s%s: %v", ip, logRequestID(c), err)
+			} else {
+				country = strings.ToUpper(strings.TrimSpace(resolved))
+			}
+		}
+
+		c.Set("client_country", country)
+		c.Next()
+	}
+}
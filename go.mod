@@ -8,6 +8,7 @@ require (
 	github.com/gin-gonic/gin v1.10.0
 	github.com/go-playground/validator/v10 v10.26.0
 	github.com/sashabaranov/go-openai v1.41.2
+	golang.org/x/sync v0.12.0
 )
 
 require (
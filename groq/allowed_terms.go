@@ -0,0 +1,66 @@
+package groq
+
+import (
+	"regexp"
+	"strings"
+)
+
+// termMatchOptions holds the client settings that affect blocked terms matching
+type termMatchOptions struct {
+	// normalizeEvasion also matches evasive spellings (see NormalizeEvasion)
+	normalizeEvasion bool
+	// allowedTerms matches the allowlisted phrases whose text is never blocked
+	allowedTerms *regexp.Regexp
+	// normalizedAllowedTerms matches the allowlisted phrases in the evasion-normalized
+	// message, so "Moby D1ck" is not blocked when "Moby Dick" is allowed
+	normalizedAllowedTerms *regexp.Regexp
+}
+
+// termMatchOptions returns the blocked terms matching settings of the client
+func (c *Client) termMatchOptions() termMatchOptions {
+	if c == nil {
+		return termMatchOptions{}
+	}
+	return termMatchOptions{
+		normalizeEvasion:       c.normalizeEvasion,
+		allowedTerms:           c.allowedTerms,
+		normalizedAllowedTerms: c.normalizedAllowedTerms,
+	}
+}
+
+// compileAllowedTerms compiles the allowlist into a single case-insensitive regex
+// matching any of the phrases literally, or nil if the allowlist is empty
+func compileAllowedTerms(terms []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// compileNormalizedAllowedTerms is compileAllowedTerms with each phrase normalized like
+// normalizeEvasion, to mask the allowlisted phrases in the normalized message
+func compileNormalizedAllowedTerms(terms []string) *regexp.Regexp {
+	normalized := make([]string, 0, len(terms))
+	for _, term := range terms {
+		normalized = append(normalized, normalizeEvasion(strings.TrimSpace(term)))
+	}
+	return compileAllowedTerms(normalized)
+}
+
+// maskAllowedTerms blanks out the allowlisted phrases in the message, so blocked
+// terms inside them (e.g., "Dick" in "Dick Tracy") are not matched while the same
+// terms elsewhere in the message still are
+func maskAllowedTerms(messageText string, allowedTerms *regexp.Regexp) string {
+	if allowedTerms == nil {
+		return messageText
+	}
+	return allowedTerms.ReplaceAllStringFunc(messageText, func(match string) string {
+		return strings.Repeat(" ", len(match))
+	})
+}
@@ -0,0 +1,45 @@
+package groq
+
+import "testing"
+
+func TestFindBlockedTermAllowedTerms(t *testing.T) {
+	blockedTerms := []string{"dick", "cunt", "pussy"}
+	allowed := []string{"Dick Tracy", "Moby Dick", "Scunthorpe", "Pussy Riot"}
+
+	plain := termMatchOptions{allowedTerms: compileAllowedTerms(allowed)}
+	evasion := termMatchOptions{
+		normalizeEvasion:       true,
+		allowedTerms:           compileAllowedTerms(allowed),
+		normalizedAllowedTerms: compileNormalizedAllowedTerms(allowed),
+	}
+
+	tests := []struct {
+		name    string
+		message string
+		opts    termMatchOptions
+		want    bool
+	}{
+		{"allowlisted name", "I watched Dick Tracy yesterday", plain, false},
+		{"allowlisted book", "Reading Moby Dick for class", plain, false},
+		{"allowlisted band", "Pussy Riot played last night", plain, false},
+		{"allowlisted town", "I live in Scunthorpe", plain, false},
+		{"blocked term alone", "what a dick", plain, true},
+		{"blocked term next to allowlisted phrase", "Dick Tracy is a dick", plain, true},
+		{"allowlisted phrase in another case", "DICK TRACY marathon", plain, false},
+		{"blocked term without allowlist", "I watched Dick Tracy yesterday", termMatchOptions{}, true},
+		{"allowlisted phrase with evasion enabled", "I watched Dick Tracy yesterday", evasion, false},
+		{"evasive spelling of allowlisted phrase", "Reading Moby D1ck for class", evasion, false},
+		{"repeated letters in allowlisted phrase", "I watched Diiick Tracy", evasion, false},
+		{"evasive blocked term alone", "what a d1ck", evasion, true},
+		{"evasive blocked term next to allowlisted phrase", "Dick Tracy is a d!ck", evasion, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, _, _ := findBlockedTerm(tt.message, blockedTerms, nil, tt.opts)
+			if found != tt.want {
+				t.Errorf("findBlockedTerm(%q) = %v; want %v", tt.message, found, tt.want)
+			}
+		})
+	}
+}
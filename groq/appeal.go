@@ -0,0 +1,99 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// appealMaxTokens leaves room for a careful explanation of the appeal verdict
+const appealMaxTokens = 400
+
+// AppealPromptTemplate generates the appeal prompt from the message and the
+// verdict being appealed
+type AppealPromptTemplate func(messageText string, original ModerationResult) string
+
+// ReCheckForAppeal re-evaluates a rejected message when the user appeals, using an
+// appeal-specific prompt that asks the model to carefully reconsider the original
+// verdict, the AppealModel (if configured) and a larger token limit
+// Merging semantics:
+//   - if the original result is not malicious there is nothing to appeal and it is returned as-is
+//   - if the appeal check fails (API error, budget, overload) the original verdict stands and the error is returned
+//   - if the model overturns the verdict, the result is not malicious and Reason explains why
+//   - if the model upholds the verdict, the result is malicious, keeping the original
+//     error code when the model does not return one
+//
+// The result has Source set to StageAppeal. Blocked terms and the cache are not consulted
+func (c *Client) ReCheckForAppeal(ctx context.Context, messageText string, originalResult ModerationResult) (ModerationResult, error) {
+	if !originalResult.IsMalicious {
+		return originalResult, nil
+	}
+	if c == nil || c.client == nil {
+		return originalResult, fmt.Errorf("groq client not initialized")
+	}
+
+	prompt := c.appealPromptBuilder(TruncateInput(messageText, c.maxInputChars), originalResult)
+	result, err := c.moderateWithModel(ctx, c.withLocaleInstruction(ctx, prompt), c.appealModel, appealMaxTokens)
+	if err != nil {
+		log.Printf("Error re-checking appealed message, original verdict stands: %v", err)
+		return originalResult, err
+	}
+	if result.failOpen {
+		// The appeal response could not be parsed, do not overturn on a parse failure
+		log.Printf("Could not parse appeal verdict, original verdict stands")
+		return originalResult, nil
+	}
+
+	result.Source = StageAppeal
+	if !result.IsMalicious {
+		log.Printf("Appeal overturned verdict: error_code=%s", originalResult.ErrorCode)
+		return result, nil
+	}
+
+	// The original code was already translated by the ErrorCodeMapper
+	if result.ErrorCode == "" || result.ErrorCode == c.defaultErrorCode {
+		result.ErrorCode = originalResult.ErrorCode
+	} else {
+		result.ErrorCode = c.mapErrorCode(result.ErrorCode)
+	}
+	if result.Reason == "" {
+		result.Reason = originalResult.Reason
+	}
+	return result, nil
+}
+
+// defaultAppealPromptTemplate asks the model to reconsider the original verdict, with
+// the JSON format using the configured response field names
+func defaultAppealPromptTemplate(fields ResponseFieldNames) AppealPromptTemplate {
+	return func(messageText string, original ModerationResult) string {
+		return fmt.Sprintf(defaultAppealPromptFormat, messageText, original.ErrorCode, original.Reason,
+			fields.IsMalicious, fields.ErrorCode, fields.Reason)
+	}
+}
+
+// defaultAppealPromptFormat is the default appeal prompt, formatted with the message,
+// the original error code and reason and the verdict, error code and reason field names
+const defaultAppealPromptFormat = `A user has appealed the rejection of their message. Carefully reconsider whether the message is really malicious, inappropriate, spam, or harmful.
+
+Message: "%[1]s"
+
+Original verdict:
+- error_code: %[2]s
+- reason: %[3]s
+
+Consider context, sarcasm, quotes, jokes between peers and false positives on words with several meanings. Overturn the verdict if the message is acceptable; uphold it only if it clearly violates the rules.
+
+Respond with ONLY a JSON object in this exact format:
+{
+  "%[4]s": true or false,
+  "%[5]s": "ERROR_CODE" or null,
+  "%[6]s": "explanation of why the verdict is upheld or overturned"
+}
+
+Error codes to use if malicious:
+- CONTENT_SPAM: for spam messages
+- CONTENT_INAPPROPRIATE: for inappropriate language or content
+- CONTENT_HARASSMENT: for harassment or bullying
+- CONTENT_SCAM: for scam or phishing attempts
+- CONTENT_VIOLENCE: for violent or threatening content
+- CONTENT_OTHER: for other malicious content`
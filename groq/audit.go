@@ -0,0 +1,43 @@
+package groq
+
+import (
+	"context"
+	"log"
+)
+
+// messageUsersKey is the context key of the sender and recipient of the message
+type messageUsersKey struct{}
+
+type messageUsers struct {
+	fromUserID int
+	toUserID   int
+}
+
+// WithMessageUsers returns a copy of ctx carrying the sender and recipient of the
+// message, passed to the AuditSaver in AuditOnly mode
+func WithMessageUsers(ctx context.Context, fromUserID int, toUserID int) context.Context {
+	return context.WithValue(ctx, messageUsersKey{}, messageUsers{fromUserID: fromUserID, toUserID: toUserID})
+}
+
+// auditResult allows a message that would have been rejected when AuditOnly is set,
+// saving the would-be rejection with the AuditSaver. Metrics were already recorded
+// with the real decision
+func (c *Client) auditResult(ctx context.Context, messageText string, result ModerationResult) ModerationResult {
+	if c == nil || !c.auditOnly || !result.IsMalicious {
+		return result
+	}
+
+	log.Printf("Audit only, allowing message that would be rejected: error_code=%s", result.ErrorCode)
+	if c.auditSaver != nil {
+		users, _ := ctx.Value(messageUsersKey{}).(messageUsers)
+		msg := newMaliciousMessage(users.fromUserID, users.toUserID, messageText, result)
+		msg.Metadata["audit"] = "true"
+		if err := SaveMaliciousMessageV2(c.auditSaver, msg); err != nil {
+			log.Printf("Error saving audited message: %v", err)
+		}
+	}
+
+	result.IsMalicious = false
+	result.Audited = true
+	return result
+}
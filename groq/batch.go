@@ -0,0 +1,70 @@
+package groq
+
+import (
+	"context"
+	"sync"
+)
+
+// defaultBatchConcurrency is the number of messages moderated in parallel by
+// CheckMessagesContent when BatchConcurrency is not set
+const defaultBatchConcurrency = 4
+
+// CheckMessagesContent moderates many messages (e.g., a whole comment thread) in one
+// call using a bounded pool of concurrent checks (see BatchConcurrency)
+// Each message goes through the same pipeline as CheckMessageContentDetailed, so
+// blocked terms are checked per message before any API call
+// Results are in the same order as messages, with Index set to the message position.
+// A failed check sets Err on its result instead of failing the batch; the returned
+// error is only set if ctx is done before every message was checked
+func (c *Client) CheckMessagesContent(ctx context.Context, messages []string) ([]ModerationResult, error) {
+	results := make([]ModerationResult, len(messages))
+	if len(messages) == 0 {
+		return results, nil
+	}
+
+	concurrency := defaultBatchConcurrency
+	if c != nil && c.batchConcurrency > 0 {
+		concurrency = c.batchConcurrency
+	}
+	if concurrency > len(messages) {
+		concurrency = len(messages)
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range indexes {
+				result, err := c.CheckMessageContentDetailed(ctx, messages[index])
+				result.Index = index
+				result.Err = err
+				results[index] = result
+			}
+		}()
+	}
+
+	// Stop handing out messages once ctx is done; the rest are marked with its error
+	sent := 0
+dispatch:
+	for sent < len(messages) {
+		select {
+		case indexes <- sent:
+			sent++
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if sent < len(messages) {
+		for index := sent; index < len(messages); index++ {
+			results[index] = ModerationResult{Index: index, Err: ctx.Err()}
+		}
+		return results, ctx.Err()
+	}
+
+	return results, nil
+}
@@ -0,0 +1,233 @@
+package groq
+
+import (
+	"crypto/sha256"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBatchMaxSize       = 100
+	defaultBatchFlushInterval = 5 * time.Second
+	// defaultBatchMaxPendingFactor sets the default MaxPending as a multiple of MaxBatchSize
+	defaultBatchMaxPendingFactor = 10
+)
+
+// MaliciousMessage is a rejected message as persisted by a MaliciousMessageSaverV2
+// or a MaliciousMessageBatchSaver. New fields can be added without breaking implementers
+type MaliciousMessage struct {
+	FromUserID  int
+	ToUserID    int
+	MessageText string
+	ErrorCode   string
+	Reason      string
+	// DetectedAt is the time the message was rejected (of the first occurrence in a batch)
+	DetectedAt time.Time
+	// CurrentTime is DetectedAt in the format "2006-01-02 15:04:05"
+	CurrentTime string
+	// Metadata holds optional details, e.g., "confidence", "severity" or "request_id"
+	Metadata map[string]string
+	// Occurrences is how many times the user sent this message within the flush window
+	// (batches only)
+	Occurrences int
+}
+
+// MaliciousMessageBatchSaver is implemented by projects that save malicious messages
+// in batches (e.g., a single multi-row INSERT)
+type MaliciousMessageBatchSaver interface {
+	// SaveMaliciousMessages saves a batch of rejected messages to the database
+	SaveMaliciousMessages(messages []MaliciousMessage) error
+}
+
+// BatchSaverConfig holds configuration for a BatchSaver
+type BatchSaverConfig struct {
+	// MaxBatchSize flushes as soon as this many distinct messages are pending (defaults to 100)
+	MaxBatchSize int
+	// FlushInterval is the flush window: pending messages are flushed at this interval (defaults to 5s)
+	FlushInterval time.Duration
+	// MaxPending bounds the distinct messages kept while the target keeps failing: a
+	// batch that fails to save is re-queued for the next flush, dropping the oldest
+	// messages beyond this limit (defaults to 10 times MaxBatchSize)
+	MaxPending int
+}
+
+// batchKey identifies repeated messages from the same user within a flush window
+type batchKey struct {
+	fromUserID  int
+	messageHash [sha256.Size]byte
+}
+
+// newBatchKey returns the batchKey of the message
+func newBatchKey(msg MaliciousMessage) batchKey {
+	return batchKey{fromUserID: msg.FromUserID, messageHash: sha256.Sum256([]byte(msg.MessageText))}
+}
+
+// BatchSaver is a MaliciousMessageSaver that buffers messages and saves them in
+// batches. Within a flush window, repeats of the same message from the same user
+// (e.g., during a spam flood) are persisted once with their Occurrences count
+// Batches that fail to save are re-queued and retried on the next flush
+// Call Close on shutdown to flush the pending messages
+type BatchSaver struct {
+	target       MaliciousMessageBatchSaver
+	maxBatchSize int
+	maxPending   int
+
+	mu      sync.Mutex
+	pending []MaliciousMessage
+	index   map[batchKey]int
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewBatchSaver creates a BatchSaver that flushes to target and starts its flush loop
+func NewBatchSaver(target MaliciousMessageBatchSaver, cfg BatchSaverConfig) *BatchSaver {
+	maxBatchSize := cfg.MaxBatchSize
+	if maxBatchSize <= 0 {
+		maxBatchSize = defaultBatchMaxSize
+	}
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = defaultBatchFlushInterval
+	}
+	maxPending := cfg.MaxPending
+	if maxPending <= 0 {
+		maxPending = maxBatchSize * defaultBatchMaxPendingFactor
+	}
+	if maxPending < maxBatchSize {
+		maxPending = maxBatchSize
+	}
+
+	s := &BatchSaver{
+		target:       target,
+		maxBatchSize: maxBatchSize,
+		maxPending:   maxPending,
+		index:        make(map[batchKey]int),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go s.flushLoop(flushInterval)
+	return s
+}
+
+// SaveMaliciousMessage buffers the message, implementing MaliciousMessageSaver
+// If the batch is full it is flushed synchronously and the flush error is returned
+func (s *BatchSaver) SaveMaliciousMessage(fromUserID int, toUserID int, messageText string, errorCode string, reason string, currentTime string) error {
+	detectedAt, _ := time.ParseInLocation(SavedMessageTimeFormat, currentTime, time.Local)
+	return s.SaveMaliciousMessageV2(MaliciousMessage{
+		FromUserID:  fromUserID,
+		ToUserID:    toUserID,
+		MessageText: messageText,
+		ErrorCode:   errorCode,
+		Reason:      reason,
+		DetectedAt:  detectedAt,
+		CurrentTime: currentTime,
+	})
+}
+
+// SaveMaliciousMessageV2 buffers the message, implementing MaliciousMessageSaverV2
+func (s *BatchSaver) SaveMaliciousMessageV2(msg MaliciousMessage) error {
+	key := newBatchKey(msg)
+
+	s.mu.Lock()
+	if i, ok := s.index[key]; ok {
+		s.pending[i].Occurrences++
+		s.mu.Unlock()
+		return nil
+	}
+
+	s.index[key] = len(s.pending)
+	msg.Occurrences = 1
+	s.pending = append(s.pending, msg)
+	// Only the message that fills the batch flushes it: with a re-queued batch pending
+	// the next flushes are left to the flush loop, instead of one per new message
+	full := len(s.pending) == s.maxBatchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush saves the pending messages now and starts a new flush window
+// If the save fails the batch is re-queued, so the next flush retries it
+func (s *BatchSaver) Flush() error {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.index = make(map[batchKey]int)
+	s.mu.Unlock()
+
+	if len(batch) == 0 || s.target == nil {
+		return nil
+	}
+	if err := s.target.SaveMaliciousMessages(batch); err != nil {
+		s.requeue(batch)
+		return err
+	}
+	return nil
+}
+
+// requeue puts a batch that failed to save back in front of the pending messages,
+// merging the Occurrences of the messages repeated since it was taken. Beyond
+// maxPending distinct messages the oldest ones are dropped and logged
+func (s *BatchSaver) requeue(batch []MaliciousMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	merged := make([]MaliciousMessage, 0, len(batch)+len(s.pending))
+	positions := make(map[batchKey]int, len(batch)+len(s.pending))
+	for _, messages := range [][]MaliciousMessage{batch, s.pending} {
+		for _, msg := range messages {
+			key := newBatchKey(msg)
+			if i, ok := positions[key]; ok {
+				merged[i].Occurrences += msg.Occurrences
+				continue
+			}
+			positions[key] = len(merged)
+			merged = append(merged, msg)
+		}
+	}
+
+	if dropped := len(merged) - s.maxPending; dropped > 0 {
+		log.Printf("Dropping %d malicious messages that could not be saved: %d pending messages limit reached", dropped, s.maxPending)
+		merged = merged[dropped:]
+	}
+
+	s.pending = merged
+	s.index = make(map[batchKey]int, len(merged))
+	for i, msg := range merged {
+		s.index[newBatchKey(msg)] = i
+	}
+}
+
+// Close stops the flush loop and flushes the pending messages
+func (s *BatchSaver) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+	})
+	return s.Flush()
+}
+
+// flushLoop flushes the pending messages at every interval until Close is called
+func (s *BatchSaver) flushLoop(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				log.Printf("Error saving malicious messages batch: %v", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
@@ -2,8 +2,12 @@ package groq
 
 import (
 	_ "embed"
+	"fmt"
+	"io"
 	"log"
+	"sort"
 	"strings"
+	"unicode"
 )
 
 // defaultBlockedTermsFile is automatically loaded at compile time from blocked_terms.txt
@@ -23,8 +27,17 @@ func defaultBlockedTerms() []string {
 		return []string{}
 	}
 
+	terms := parseBlockedTerms(defaultBlockedTermsFile)
+
+	log.Printf("Loaded %d blocked terms from blocked_terms.txt", len(terms))
+	return terms
+}
+
+// parseBlockedTerms parses a blocked terms list with one term per line
+// Empty lines and comments (lines starting with #) are skipped
+func parseBlockedTerms(content string) []string {
 	// Split by newlines and filter empty lines
-	lines := strings.Split(defaultBlockedTermsFile, "\n")
+	lines := strings.Split(content, "\n")
 	terms := make([]string, 0, len(lines))
 
 	for _, line := range lines {
@@ -35,15 +48,145 @@ func defaultBlockedTerms() []string {
 		}
 	}
 
-	log.Printf("Loaded %d blocked terms from blocked_terms.txt", len(terms))
 	return terms
 }
 
+// ExportBlockedTerms writes the client's effective blocked terms to w, one per line,
+// in the same format as blocked_terms.txt
+func (c *Client) ExportBlockedTerms(w io.Writer) error {
+	for _, term := range c.getBlockedTerms() {
+		if _, err := fmt.Fprintln(w, term); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportBlockedTerms replaces the client's blocked terms with the list read from r
+// The format is the same as blocked_terms.txt (one term per line, # for comments)
+func (c *Client) ImportBlockedTerms(r io.Reader) error {
+	if c == nil {
+		return fmt.Errorf("groq client not initialized")
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("error reading blocked terms: %w", err)
+	}
+
+	terms := parseBlockedTerms(string(content))
+	c.setBlockedTerms(terms)
+	log.Printf("Imported %d blocked terms", len(terms))
+	return nil
+}
+
+// getBlockedTerms returns the current blocked terms list
+func (c *Client) getBlockedTerms() []string {
+	terms, _ := c.getBlockedTermSet()
+	return terms
+}
+
+// getBlockedTermSet returns the current blocked terms list with its compiled regex terms
+func (c *Client) getBlockedTermSet() ([]string, termRegexps) {
+	if c == nil {
+		return nil, nil
+	}
+	c.termsMu.RLock()
+	defer c.termsMu.RUnlock()
+	return c.blockedTerms, c.termRegexps
+}
+
+// setBlockedTerms atomically replaces the blocked terms list and its compiled regex
+// terms, keeping the appended terms
+func (c *Client) setBlockedTerms(terms []string) {
+	regexps := compileTermRegexps(terms)
+
+	c.termsMu.Lock()
+	defer c.termsMu.Unlock()
+	c.blockedTerms = mergeBlockedTerms(terms, c.appendedTerms)
+	c.termRegexps = regexps.with(c.appendedRegexps)
+}
+
+// AddBlockedTerms adds terms to the client's current list at runtime, skipping the
+// ones already present (case-insensitive). Like AppendBlockedTerms, they are kept
+// when the list is reloaded or imported
+func (c *Client) AddBlockedTerms(terms ...string) {
+	if c == nil {
+		return
+	}
+	regexps := compileTermRegexps(terms)
+
+	c.termsMu.Lock()
+	defer c.termsMu.Unlock()
+	c.appendedTerms = mergeBlockedTerms(c.appendedTerms, terms)
+	c.appendedRegexps = c.appendedRegexps.with(regexps)
+	c.blockedTerms = mergeBlockedTerms(c.blockedTerms, terms)
+	c.termRegexps = c.termRegexps.with(regexps)
+}
+
+// mergeBlockedTerms returns the terms of base followed by the extra terms not already
+// present, comparing case-insensitively. base is not modified
+func mergeBlockedTerms(base []string, extra []string) []string {
+	if len(extra) == 0 {
+		return base
+	}
+
+	merged := make([]string, 0, len(base)+len(extra))
+	seen := make(map[string]bool, len(base)+len(extra))
+	for _, list := range [][]string{base, extra} {
+		for _, term := range list {
+			term = strings.TrimSpace(term)
+			key := strings.ToLower(term)
+			if term == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			merged = append(merged, term)
+		}
+	}
+	return merged
+}
+
+// defaultTermCode is the error code of blocked terms without a category
+const defaultTermCode = "CONTENT_INAPPROPRIATE"
+
+// termCategoryCodes maps the optional category prefix of a blocked term
+// (e.g., "harassment:slur1", "spam:buy now") to its error code
+var termCategoryCodes = map[string]string{
+	"spam":          "CONTENT_SPAM",
+	"inappropriate": "CONTENT_INAPPROPRIATE",
+	"harassment":    "CONTENT_HARASSMENT",
+	"scam":          "CONTENT_SCAM",
+	"violence":      "CONTENT_VIOLENCE",
+	"other":         "CONTENT_OTHER",
+}
+
+// termEntry is a blocked terms list entry split into its error code and term
+type termEntry struct {
+	code string
+	term string
+}
+
+// parseTermEntry splits the optional category prefix off a blocked term. Only the
+// known categories are recognized, so other terms containing ":" are kept as written
+func parseTermEntry(raw string) termEntry {
+	trimmed := strings.TrimSpace(raw)
+	if category, term, ok := strings.Cut(trimmed, ":"); ok {
+		if code, known := termCategoryCodes[strings.ToLower(category)]; known {
+			return termEntry{code: code, term: strings.TrimSpace(term)}
+		}
+	}
+	return termEntry{code: defaultTermCode, term: trimmed}
+}
+
 // containsBlockedTerm checks if the message contains any of the blocked terms
-// Performs case-insensitive matching
-func containsBlockedTerm(messageText string, blockedTerms []string) (bool, string) {
+// Performs case-insensitive matching. Terms prefixed with "re:" are matched as regexes
+// against the original message; the matched term is returned as written
+// The error code is that of the term category (CONTENT_INAPPROPRIATE if uncategorized)
+// regexps are the compiled regex terms of the list (nil to compile them on demand)
+func containsBlockedTerm(messageText string, blockedTerms []string, regexps termRegexps) (bool, string, string) {
 	if len(blockedTerms) == 0 {
-		return false, ""
+		return false, "", ""
 	}
 
 	messageLower := strings.ToLower(messageText)
@@ -53,8 +196,16 @@ func containsBlockedTerm(messageText string, blockedTerms []string) (bool, strin
 	normalizedMessage = strings.ReplaceAll(normalizedMessage, "-", " ")
 
 	// Check each blocked term
-	for _, term := range blockedTerms {
-		termLower := strings.ToLower(strings.TrimSpace(term))
+	for _, raw := range blockedTerms {
+		entry := parseTermEntry(raw)
+		if isRegexTerm(entry.term) {
+			if matchRegexTerm(messageText, entry.term, regexps) {
+				return true, entry.term, entry.code
+			}
+			continue
+		}
+
+		termLower := strings.ToLower(entry.term)
 		if termLower == "" {
 			continue
 		}
@@ -65,12 +216,46 @@ func containsBlockedTerm(messageText string, blockedTerms []string) (bool, strin
 			// Additional validation: check if it's a word boundary
 			// This helps avoid false positives (e.g., "class" in "classroom")
 			if isWholeWord(messageLower, termLower) || isWholeWord(normalizedMessage, termLower) {
-				return true, termLower
+				return true, termLower, entry.code
 			}
 		}
 	}
 
-	return false, ""
+	return false, "", ""
+}
+
+// ContainsBlockedTerm reports whether the message contains any of the blocked terms
+// and returns the matched term. It is the local, synchronous check used before the
+// AI call, with the same whole-word, category and "re:" semantics
+func ContainsBlockedTerm(messageText string, blockedTerms []string) (bool, string) {
+	found, term, _ := containsBlockedTerm(messageText, blockedTerms, nil)
+	return found, term
+}
+
+// ContainsBlockedTerm is like the package-level ContainsBlockedTerm using the client's
+// current blocked terms, allowed terms and evasion normalization (no AI call)
+func (c *Client) ContainsBlockedTerm(messageText string) (bool, string) {
+	terms, regexps := c.getBlockedTermSet()
+	found, term, _ := findBlockedTerm(messageText, terms, regexps, c.termMatchOptions())
+	return found, term
+}
+
+// FindBlockedTerms returns every distinct blocked term found in the message, in list
+// order, with the same whole-word, case-insensitive semantics as containsBlockedTerm
+// The hot path keeps using the single-match check; this is for review dashboards
+// that need to tell a message hitting many terms from one borderline term
+func FindBlockedTerms(messageText string, blockedTerms []string) []string {
+	var found []string
+	seen := make(map[string]bool)
+	for _, raw := range blockedTerms {
+		matched, term, _ := containsBlockedTerm(messageText, []string{raw}, nil)
+		if !matched || seen[term] {
+			continue
+		}
+		seen[term] = true
+		found = append(found, term)
+	}
+	return found
 }
 
 // isWholeWord checks if the term appears as a whole word in the message
@@ -115,3 +300,120 @@ func isWholeWord(message, term string) bool {
 func isAlphanumeric(b byte) bool {
 	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
 }
+
+// containsBlockedSubstring checks if the text contains any of the blocked terms as a
+// substring, ignoring case and separators. Unlike containsBlockedTerm it does not
+// require word boundaries, which is the stricter behavior needed for usernames
+// where words are usually joined together (e.g., "xxslurxx").
+// Digits are ignored for terms with letters (so "s1l2u3r" matches "slur"), while
+// terms made only of digits are matched against the text with its digits kept
+func containsBlockedSubstring(text string, blockedTerms []string, regexps termRegexps) (bool, string) {
+	if len(blockedTerms) == 0 {
+		return false, ""
+	}
+
+	normalizedText := normalizeUsername(text)
+	textWithDigits := stripUsernameSeparators(text)
+
+	for _, raw := range blockedTerms {
+		term := parseTermEntry(raw).term
+		if isRegexTerm(term) {
+			if matchRegexTerm(text, term, regexps) {
+				return true, term
+			}
+			continue
+		}
+
+		termNormalized := normalizeUsername(term)
+		if termNormalized == "" {
+			// Numeric term (e.g., "1488"): stripping digits would leave nothing to match
+			termWithDigits := stripUsernameSeparators(term)
+			if termWithDigits != "" && strings.Contains(textWithDigits, termWithDigits) {
+				return true, strings.ToLower(term)
+			}
+			continue
+		}
+		if strings.Contains(normalizedText, termNormalized) {
+			return true, strings.ToLower(term)
+		}
+	}
+
+	return false, ""
+}
+
+// Match is a blocked term found in a message
+// Start and End are rune offsets into the original message (End is exclusive),
+// so the frontend can highlight the exact offending span
+type Match struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// FindBlockedTermMatches returns every whole-word occurrence of the blocked terms in
+// the message, sorted by position, plus the matches of "re:" regex terms. Matching is case-insensitive and treats "_" and
+// "-" as spaces, like containsBlockedTerm, but is rune-aware so offsets are correct
+// for accented and other non-ASCII characters
+func FindBlockedTermMatches(message string, terms []string) []Match {
+	messageRunes := normalizeMatchRunes(message)
+
+	var matches []Match
+	for _, raw := range terms {
+		term := parseTermEntry(raw).term
+		if isRegexTerm(term) {
+			matches = append(matches, findRegexTermMatches(message, term, nil)...)
+			continue
+		}
+
+		termRunes := normalizeMatchRunes(term)
+		if len(termRunes) == 0 {
+			continue
+		}
+
+		for start := 0; start+len(termRunes) <= len(messageRunes); start++ {
+			end := start + len(termRunes)
+			if !runesEqual(messageRunes[start:end], termRunes) {
+				continue
+			}
+			beforeOK := start == 0 || !isWordRune(messageRunes[start-1])
+			afterOK := end == len(messageRunes) || !isWordRune(messageRunes[end])
+			if beforeOK && afterOK {
+				matches = append(matches, Match{Term: strings.ToLower(term), Start: start, End: end})
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+	return matches
+}
+
+// normalizeMatchRunes lowercases the text rune by rune (keeping rune offsets stable)
+// and replaces common separators with spaces
+func normalizeMatchRunes(text string) []rune {
+	runes := []rune(text)
+	for i, r := range runes {
+		if r == '_' || r == '-' {
+			runes[i] = ' '
+			continue
+		}
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+// runesEqual compares two rune slices of the same length
+func runesEqual(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// isWordRune checks if a rune is a letter or a digit in any script
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
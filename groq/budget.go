@@ -0,0 +1,90 @@
+package groq
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// errBudgetExceeded is returned by moderateWithAI when the daily budget is exhausted
+var errBudgetExceeded = errors.New("daily moderation budget exceeded")
+
+// budgetTracker counts AI calls and tokens per day to cap moderation spend
+// The counters reset at midnight in the configured location (UTC by default)
+type budgetTracker struct {
+	mu          sync.Mutex
+	callLimit   int
+	tokenLimit  int
+	location    *time.Location
+	periodStart time.Time
+	calls       int
+	tokens      int
+	warned      bool
+}
+
+// newBudgetTracker returns nil if no budget is configured
+func newBudgetTracker(callLimit, tokenLimit int, location *time.Location) *budgetTracker {
+	if callLimit <= 0 && tokenLimit <= 0 {
+		return nil
+	}
+	if location == nil {
+		location = time.UTC
+	}
+	return &budgetTracker{
+		callLimit:  callLimit,
+		tokenLimit: tokenLimit,
+		location:   location,
+	}
+}
+
+// reserveCall counts a new AI call, returning false if the daily budget is exhausted
+// A nil tracker always allows the call
+func (b *budgetTracker) reserveCall() bool {
+	if b == nil {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfNewPeriod()
+
+	if (b.callLimit > 0 && b.calls >= b.callLimit) || (b.tokenLimit > 0 && b.tokens >= b.tokenLimit) {
+		if !b.warned {
+			log.Printf("WARNING: daily moderation budget exceeded (calls=%d/%d, tokens=%d/%d), using blocked terms only until %s",
+				b.calls, b.callLimit, b.tokens, b.tokenLimit, b.periodStart.AddDate(0, 0, 1).Format(time.RFC3339))
+			b.warned = true
+		}
+		return false
+	}
+
+	b.calls++
+	return true
+}
+
+// addTokens records the tokens used by a completed AI call
+func (b *budgetTracker) addTokens(tokens int) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.resetIfNewPeriod()
+	b.tokens += tokens
+}
+
+// resetIfNewPeriod resets the counters when the day changes. Must be called with the lock held
+func (b *budgetTracker) resetIfNewPeriod() {
+	now := time.Now().In(b.location)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, b.location)
+	if start.Equal(b.periodStart) {
+		return
+	}
+	b.periodStart = start
+	b.calls = 0
+	b.tokens = 0
+	b.warned = false
+}
@@ -0,0 +1,123 @@
+package groq
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheSize is the maximum number of cached verdicts when CacheSize is not set
+const defaultCacheSize = 10000
+
+// resultCache is an LRU cache of moderation verdicts for identical messages
+// Only confident verdicts must be stored: fail-open results (API errors, unparseable
+// responses, fallbacks) would otherwise let identical spam through for the full TTL
+type resultCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[string]*list.Element
+	order   *list.List // front is the most recently used
+}
+
+type cacheEntry struct {
+	key       string
+	result    ModerationResult
+	expiresAt time.Time
+}
+
+// newResultCache returns nil if caching is disabled (ttl <= 0)
+// A size <= 0 uses the default size
+func newResultCache(ttl time.Duration, size int) *resultCache {
+	if ttl <= 0 {
+		return nil
+	}
+	if size <= 0 {
+		size = defaultCacheSize
+	}
+	return &resultCache{
+		ttl:     ttl,
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// get returns the cached verdict for the key, if present and not expired
+func (rc *resultCache) get(key string) (ModerationResult, bool) {
+	if rc == nil {
+		return ModerationResult{}, false
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	element, ok := rc.entries[key]
+	if !ok {
+		return ModerationResult{}, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		rc.order.Remove(element)
+		delete(rc.entries, key)
+		return ModerationResult{}, false
+	}
+	rc.order.MoveToFront(element)
+	return entry.result, true
+}
+
+// set stores a verdict, evicting the least recently used one if the cache is full
+// Fail-open verdicts are never written
+func (rc *resultCache) set(key string, result ModerationResult) {
+	if rc == nil || result.failOpen {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	expiresAt := time.Now().Add(rc.ttl)
+	if element, ok := rc.entries[key]; ok {
+		entry := element.Value.(*cacheEntry)
+		entry.result = result
+		entry.expiresAt = expiresAt
+		rc.order.MoveToFront(element)
+		return
+	}
+
+	rc.entries[key] = rc.order.PushFront(&cacheEntry{key: key, result: result, expiresAt: expiresAt})
+	for rc.order.Len() > rc.size {
+		oldest := rc.order.Back()
+		rc.order.Remove(oldest)
+		delete(rc.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// clear drops every cached verdict, e.g., after the prompt changes
+func (rc *resultCache) clear() {
+	if rc == nil {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries = make(map[string]*list.Element)
+	rc.order.Init()
+}
+
+// cacheKey returns the hash of the message text used as cache key
+func cacheKey(messageText string) string {
+	sum := sha256.Sum256([]byte(messageText))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeCacheText normalizes the message for the cache key, so messages that only
+// differ in case or whitespace ("Hi ", "hi") share a verdict
+// Case-sensitive local checks (e.g., shouting patterns) run before the cache
+func normalizeCacheText(messageText string) string {
+	return strings.ToLower(strings.Join(strings.Fields(messageText), " "))
+}
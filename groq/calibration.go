@@ -0,0 +1,43 @@
+package groq
+
+import (
+	"math/rand"
+)
+
+// calibrationSampler emits a sample of moderation results for offline calibration
+type calibrationSampler struct {
+	rate float64
+	sink func(ModerationResult)
+}
+
+// WithCalibrationSampler emits, for a fraction rate (0 to 1) of the checked messages,
+// the full result (including Confidence) to sink, e.g., to store it with the eventual
+// human decision and tune thresholds offline. The sink is called synchronously from
+// the check, so it must be fast (e.g., push to a buffered channel)
+// A rate <= 0 or a nil sink disables sampling. Returns the client for chaining
+func (c *Client) WithCalibrationSampler(rate float64, sink func(ModerationResult)) *Client {
+	if c == nil {
+		return nil
+	}
+	if rate <= 0 || sink == nil {
+		c.calibration.Store(nil)
+		return c
+	}
+	if rate > 1 {
+		rate = 1
+	}
+	c.calibration.Store(&calibrationSampler{rate: rate, sink: sink})
+	return c
+}
+
+// sampleForCalibration emits the result to the calibration sink if it is sampled
+func (c *Client) sampleForCalibration(result ModerationResult) {
+	if c == nil {
+		return
+	}
+	sampler := c.calibration.Load()
+	if sampler == nil || rand.Float64() >= sampler.rate {
+		return
+	}
+	sampler.sink(result)
+}
@@ -3,25 +3,130 @@ package groq
 import (
 	"fmt"
 	"log"
+	"net/http"
 	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"golang.org/x/sync/singleflight"
 )
 
 // PromptTemplate is a function that generates a prompt from a message text
 type PromptTemplate func(messageText string) string
 
+// DefaultErrorCode is the error code of malicious verdicts returned without a code
+const DefaultErrorCode = "CONTENT_OTHER"
+
+// ErrorCodeMapper translates an error code (e.g., "CONTENT_SPAM") into the caller's
+// own taxonomy, e.g., numeric codes
+type ErrorCodeMapper func(aiCode string) string
+
+// RequestCustomizer is a function that modifies the chat completion request before it is sent
+type RequestCustomizer func(request *openai.ChatCompletionRequest)
+
+// UsageCallback receives the token usage of each successful API call, e.g., to bill
+// teams for their moderation usage or estimate Groq costs
+type UsageCallback func(promptTokens, completionTokens int, model string)
+
 // Client wraps the Groq OpenAI-compatible client
 type Client struct {
-	client        *openai.Client
-	model         string
-	promptBuilder PromptTemplate
-	blockedTerms  []string
+	client          *openai.Client
+	model           string
+	promptMu        sync.RWMutex
+	promptBuilder   PromptTemplate
+	promptTemplates map[string]PromptTemplate
+
+	termsMu         sync.RWMutex
+	blockedTerms    []string
+	termRegexps     termRegexps
+	appendedTerms   []string
+	appendedRegexps termRegexps
+
+	blockedPatterns []blockedPattern
+
+	usernamePromptBuilder PromptTemplate
+	reservedUsernames     []string
+	usernameMinLength     int
+	usernameMaxLength     int
+
+	responseFields ResponseFieldNames
+	categoryCodes  map[string]string
+
+	budget      *budgetTracker
+	cache       *resultCache
+	inflight    singleflight.Group
+	sharedCalls sharedCalls
+
+	requestCustomizer RequestCustomizer
+	usageCallback     UsageCallback
+
+	decodeObfuscated bool
+	decodeMinLength  int
+
+	nonBlockingCodes map[string]bool
+
+	metricsObserver MetricsObserver
+	metrics         Metrics
+
+	maxInputChars int
+
+	reasonVerbosity ReasonVerbosity
+
+	blockedDomains []string
+	allowedDomains []string
+
+	localeInstruction LocaleInstruction
+
+	appealPromptBuilder AppealPromptTemplate
+	appealModel         string
+
+	contextPromptTemplate ContextPromptTemplate
+	maxHistoryTurns       int
+	maxHistoryChars       int
+
+	visionModel       string
+	allowedImageHosts []string
+
+	errorCodeMapper  ErrorCodeMapper
+	defaultErrorCode string
+
+	jsonMode            bool
+	jsonModeUnsupported sync.Map
+
+	auditOnly  bool
+	auditSaver MaliciousMessageSaver
+
+	saver MaliciousMessageSaver
+
+	rejectWhitespaceOnly bool
+
+	calibration atomic.Pointer[calibrationSampler]
+
+	maxRetries     int
+	retryBaseDelay time.Duration
+
+	batchConcurrency int
+
+	requestTimeout      time.Duration
+	failClosedOnTimeout bool
+
+	normalizeEvasion       bool
+	allowedTerms           *regexp.Regexp
+	normalizedAllowedTerms *regexp.Regexp
+	skipBlockedTerms       bool
+
+	profilesMu sync.RWMutex
+	profiles   map[string]registeredProfile
 }
 
 // Config holds configuration for the Groq client
 type Config struct {
 	// APIKey is the Groq API key (read from GROQ_API_KEY env var if empty)
+	// Values read from the environment are trimmed of whitespace and surrounding quotes
 	APIKey string
 	// Model is the Groq model to use (read from GROQ_MODEL env var if empty, defaults to "llama-3.1-8b-instant")
 	Model string
@@ -30,10 +135,180 @@ type Config struct {
 	// PromptTemplate is a function that generates the prompt for content moderation
 	// If not provided, a default prompt will be used
 	PromptTemplate PromptTemplate
+	// PromptTemplates are prompt templates per language, keyed by ISO code (e.g., "es",
+	// "pt" or "pt-BR"), used when the message locale is known (see WithLocale and
+	// CheckMessageContentLang). Other languages use PromptTemplate
+	PromptTemplates map[string]PromptTemplate
 	// BlockedTerms is a list of offensive terms to check before using AI
 	// If not provided, a default list will be used
 	// If empty slice is provided, blocked terms checking will be disabled
+	// Entries prefixed with "re:" are case-insensitive regexes (e.g., "re:f[\W_]*u[\W_]*c[\W_]*k");
+	// invalid ones are logged and skipped
 	BlockedTerms []string
+	// AppendBlockedTerms are project-specific terms added to BlockedTerms (or to the
+	// default list when BlockedTerms is nil), deduplicated case-insensitively. They
+	// are kept when the list is reloaded or imported. With an empty BlockedTerms
+	// only these terms are checked
+	AppendBlockedTerms []string
+	// BlockedPatterns are regexes (Go RE2 syntax) checked after the blocked terms, for
+	// abuse that literal terms cannot express (shouting, URL shorteners...)
+	// Each pattern may start with its error code (e.g., "CONTENT_SCAM:(?i)bit\.ly/");
+	// without it the code is CONTENT_SPAM. Patterns are compiled once and limited to
+	// 512 characters; invalid ones are logged and skipped (use Validate to fail fast)
+	BlockedPatterns []string
+	// UsernamePromptTemplate is a function that generates the prompt for username moderation
+	// If not provided, a default username prompt will be used
+	UsernamePromptTemplate PromptTemplate
+	// ReservedUsernames is a list of names that usernames cannot start or end with (e.g., "admin")
+	// If not provided, a default list will be used
+	// If empty slice is provided, reserved names checking will be disabled
+	ReservedUsernames []string
+	// UsernameMinLength is the minimum username length (defaults to 3)
+	UsernameMinLength int
+	// UsernameMaxLength is the maximum username length (defaults to 30)
+	UsernameMaxLength int
+	// ResponseFields holds the JSON field names expected in the model response
+	// Empty names default to "is_malicious", "error_code" and "reason"
+	ResponseFields ResponseFieldNames
+	// CategoryCodes maps categories returned by the model (e.g., "spam") to error codes
+	// (e.g., "CONTENT_SPAM"). Matching is case-insensitive; unmapped categories are returned as-is
+	CategoryCodes map[string]string
+	// DailyCallBudget is the maximum number of AI calls per day (0 means unlimited)
+	// Once exceeded, moderation falls back to blocked terms only until the daily reset
+	DailyCallBudget int
+	// DailyTokenBudget is the maximum number of tokens used per day (0 means unlimited)
+	// Once exceeded, moderation falls back to blocked terms only until the daily reset
+	DailyTokenBudget int
+	// BudgetLocation is the location whose midnight resets the daily budget (defaults to UTC)
+	BudgetLocation *time.Location
+	// CacheTTL enables caching of moderation verdicts for identical messages (0 disables caching)
+	// Only confident verdicts are cached; results allowed because of an error are never cached
+	CacheTTL time.Duration
+	// CacheSize is the maximum number of cached verdicts; the least recently used are
+	// evicted first (defaults to 10000). Messages are normalized (case and whitespace)
+	// before hashing, so "Hi " and "hi" share a verdict
+	CacheSize int
+	// RequestCustomizer is applied to every chat completion request just before it is sent,
+	// after the defaults are set. It allows tweaking parameters not exposed by Config
+	// (e.g., LogitBias, FrequencyPenalty, extra messages)
+	// Misuse can break response parsing (e.g., removing the prompt or lowering MaxTokens)
+	RequestCustomizer RequestCustomizer
+	// UsageCallback is called with the prompt and completion tokens and the model after
+	// each successful API call, including moderations, usernames, appeals and translations
+	// It runs synchronously, so it should be fast (e.g., incrementing counters)
+	UsageCallback UsageCallback
+	// DecodeObfuscatedContent enables decoding base64 and URL-encoded substrings and
+	// re-moderating the decoded content. It is more expensive since each decoded
+	// payload may require an additional AI call
+	DecodeObfuscatedContent bool
+	// DecodeMinLength is the minimum length of an encoded substring to be decoded (defaults to 16)
+	DecodeMinLength int
+	// NonBlockingCodes are error codes (e.g., "CONTENT_SPAM") that are soft-handled:
+	// CheckMessageContent returns isMalicious=false but still populates the error code
+	// and reason, and CheckMessageContentDetailed sets Warning. Matching is case-insensitive
+	NonBlockingCodes []string
+	// MetricsObserver receives the pipeline stage that decided each verdict
+	// (blocked terms, cache, AI or fallback). If not provided, events are discarded
+	MetricsObserver MetricsObserver
+	// Metrics receives blocked counters by stage, check latencies and API errors
+	// If not provided, they are discarded
+	Metrics Metrics
+	// MaxInputChars truncates messages longer than this before building the prompt,
+	// keeping the start and the end (0 disables truncation). See TruncateInput
+	MaxInputChars int
+	// ReasonVerbosity sets how detailed the reason of a verdict is: terse for end users
+	// (default), detailed for moderators or none for logs. With ReasonVerbosityNone the
+	// default prompt does not ask for a reason (saving tokens) and Reason is left empty.
+	// Custom prompt templates are not modified
+	ReasonVerbosity ReasonVerbosity
+	// BlockedDomains are domains whose links (including subdomains) are rejected
+	// locally as CONTENT_SCAM without an AI call
+	BlockedDomains []string
+	// AllowedDomains are our own and trusted partners' domains. Links to them (including
+	// subdomains) are never flagged: they bypass BlockedDomains and are replaced with a
+	// neutral placeholder before the AI call
+	AllowedDomains []string
+	// LocaleInstruction builds the instruction appended to the prompt when the context
+	// carries a locale (see WithLocale). If not provided, a default instruction naming
+	// the language and region is used (e.g., "The message is in Mexican Spanish; ...")
+	LocaleInstruction LocaleInstruction
+	// AppealPromptTemplate generates the prompt used by ReCheckForAppeal
+	// If not provided, a default appeal prompt will be used
+	AppealPromptTemplate AppealPromptTemplate
+	// ContextPromptTemplate renders the message and the conversation history used by
+	// CheckMessageContentWithContext. If not provided, the history is prepended to the
+	// regular prompt
+	ContextPromptTemplate ContextPromptTemplate
+	// MaxHistoryTurns is the number of most recent turns kept by CheckMessageContentWithContext
+	// (defaults to DefaultMaxHistoryTurns)
+	MaxHistoryTurns int
+	// MaxHistoryChars bounds the total characters of the kept turns, dropping the oldest
+	// first to keep token usage in check (defaults to DefaultMaxHistoryChars)
+	MaxHistoryChars int
+	// VisionModel is the vision-capable model used by CheckImageContent
+	// (defaults to DefaultVisionModel)
+	VisionModel string
+	// AllowedImageHosts restricts the hosts of the image URLs sent by CheckImageContent
+	// (subdomains included, e.g., "talentpitch.co"). If empty, any https URL is sent
+	AllowedImageHosts []string
+	// ErrorCodeMapper translates every non-empty error code before it is returned,
+	// including local ones and DefaultErrorCode. NonBlockingCodes and the cache use
+	// the original codes. If not provided, codes are returned as-is
+	ErrorCodeMapper ErrorCodeMapper
+	// DefaultErrorCode is the code of malicious verdicts the model returns without one
+	// (defaults to DefaultErrorCode)
+	DefaultErrorCode string
+	// JSONMode sets response_format to json_object so the model returns valid JSON,
+	// avoiding markdown fences and prose around the verdict. Models that reject it are
+	// retried once without it and remembered. Disabled by default, since not all
+	// models accept it
+	JSONMode bool
+	// AuditOnly checks and records messages without rejecting them: CheckMessageContent
+	// always returns isMalicious=false and CheckMessageContentDetailed sets Audited on
+	// would-be rejections. Metrics get the real decision, e.g., to measure false
+	// positives on production traffic before enforcing
+	AuditOnly bool
+	// AuditSaver saves the would-be rejections in AuditOnly mode, with the users set
+	// by WithMessageUsers (0 if not set). If not provided, they are only logged
+	AuditSaver MaliciousMessageSaver
+	// MaliciousMessageSaver persists the messages rejected by CheckAndSave
+	// If not provided, CheckAndSave only checks
+	MaliciousMessageSaver MaliciousMessageSaver
+	// AppealModel is the model used by ReCheckForAppeal, e.g., a stronger one (defaults to Model)
+	AppealModel string
+	// RejectWhitespaceOnly rejects whitespace-only messages with CONTENT_EMPTY instead of
+	// allowing them. Empty and whitespace-only messages are never sent to the model
+	RejectWhitespaceOnly bool
+	// MaxRetries is the number of retries of transient API errors (rate limits, 5xx,
+	// over capacity) before giving up (0 disables retries). Retries respect the context
+	MaxRetries int
+	// RetryBaseDelay is the first retry delay, doubled on each retry with jitter and
+	// capped at 10s; a longer Retry-After hint from the provider is honored (defaults to 200ms)
+	RetryBaseDelay time.Duration
+	// BatchConcurrency is the number of messages CheckMessagesContent moderates in
+	// parallel (defaults to 4). Keep it low enough for the Groq rate limits
+	BatchConcurrency int
+	// RequestTimeout bounds each moderation check whose context has no deadline of its
+	// own (0 disables it, relying on the caller's context). When it fires, the message is
+	// allowed with the local verdict (fail open), unless FailClosedOnTimeout is set
+	RequestTimeout time.Duration
+	// FailClosedOnTimeout returns the RequestTimeout expiry as an error wrapping
+	// context.DeadlineExceeded instead of allowing the message, so callers such as the
+	// acceptable validator reject it
+	FailClosedOnTimeout bool
+	// NormalizeEvasion also checks the blocked terms against a normalized message that
+	// undoes leetspeak (0->o, 1->i, 3->e, $->s, @->a...), strips zero-width and combining
+	// characters and collapses repeated letters, catching "a$$hole" or "fuuuck"
+	// It can increase false positives, so it is disabled by default
+	NormalizeEvasion bool
+	// AllowedTerms are phrases that are never blocked even if they contain a blocked
+	// term, for names and places that trip the filter (e.g., "Dick Tracy", "Scunthorpe")
+	// Matching is case-insensitive; blocked terms elsewhere in the message still match
+	AllowedTerms []string
+	// SkipBlockedTerms disables the blocked terms fast path for callers who only want
+	// the AI verdict, keeping the list loaded (e.g., for ExportBlockedTerms or usernames)
+	// Profiles with their own BlockedTerms still use them
+	SkipBlockedTerms bool
 }
 
 // NewClient creates a new Groq client with the given configuration
@@ -42,7 +317,7 @@ type Config struct {
 func NewClient(cfg Config) *Client {
 	apiKey := cfg.APIKey
 	if apiKey == "" {
-		apiKey = os.Getenv("GROQ_API_KEY")
+		apiKey = getEnv("GROQ_API_KEY")
 	}
 
 	if apiKey == "" {
@@ -52,7 +327,7 @@ func NewClient(cfg Config) *Client {
 
 	model := cfg.Model
 	if model == "" {
-		model = os.Getenv("GROQ_MODEL")
+		model = getEnv("GROQ_MODEL")
 		if model == "" {
 			model = "llama-3.1-8b-instant"
 		}
@@ -66,12 +341,14 @@ func NewClient(cfg Config) *Client {
 	// Create default config and set custom base URL for Groq
 	openaiConfig := openai.DefaultConfig(apiKey)
 	openaiConfig.BaseURL = baseURL
+	// Capture the Retry-After header of rate limited calls (see RateLimitError)
+	openaiConfig.HTTPClient = &http.Client{Transport: &retryAfterTransport{base: http.DefaultTransport}}
 	client := openai.NewClientWithConfig(openaiConfig)
 
 	// Set prompt template (use default if not provided)
 	promptBuilder := cfg.PromptTemplate
 	if promptBuilder == nil {
-		promptBuilder = defaultPromptTemplate
+		promptBuilder = defaultPromptTemplateWithVerbosity(cfg.ReasonVerbosity, cfg.ResponseFields.withDefaults())
 	}
 
 	// Set blocked terms (use default if not provided)
@@ -81,28 +358,219 @@ func NewClient(cfg Config) *Client {
 		blockedTerms = defaultBlockedTerms()
 	}
 	// If empty slice is provided, blocked terms checking is disabled
+	blockedTerms = mergeBlockedTerms(blockedTerms, cfg.AppendBlockedTerms)
+	// Regex terms ("re:...") are compiled once here; invalid ones are logged and skipped
+	regexps := compileTermRegexps(blockedTerms)
+
+	// Compile blocked patterns once
+	blockedPatterns, err := compileBlockedPatterns(cfg.BlockedPatterns)
+	if err != nil {
+		log.Printf("Skipping invalid blocked patterns: %v", err)
+	}
+
+	// Set username moderation settings (use defaults if not provided)
+	usernamePromptBuilder := cfg.UsernamePromptTemplate
+	if usernamePromptBuilder == nil {
+		usernamePromptBuilder = defaultUsernamePromptTemplate(cfg.ResponseFields.withDefaults())
+	}
+	reservedUsernames := cfg.ReservedUsernames
+	if reservedUsernames == nil {
+		reservedUsernames = defaultReservedUsernames()
+	}
+	usernameMinLength := cfg.UsernameMinLength
+	if usernameMinLength <= 0 {
+		usernameMinLength = defaultUsernameMinLength
+	}
+	usernameMaxLength := cfg.UsernameMaxLength
+	if usernameMaxLength <= 0 {
+		usernameMaxLength = defaultUsernameMaxLength
+	}
+
+	// Set locale instruction (use default if not provided)
+	localeInstruction := cfg.LocaleInstruction
+	if localeInstruction == nil {
+		localeInstruction = defaultLocaleInstruction
+	}
+
+	// Set appeal settings (use defaults if not provided)
+	appealPromptBuilder := cfg.AppealPromptTemplate
+	if appealPromptBuilder == nil {
+		appealPromptBuilder = defaultAppealPromptTemplate(cfg.ResponseFields.withDefaults())
+	}
+	appealModel := cfg.AppealModel
+	if appealModel == "" {
+		appealModel = model
+	}
+
+	// Set vision settings (use defaults if not provided)
+	visionModel := cfg.VisionModel
+	if visionModel == "" {
+		visionModel = DefaultVisionModel
+	}
+	var allowedImageHosts []string
+	for _, host := range cfg.AllowedImageHosts {
+		if host = strings.ToLower(strings.TrimSpace(host)); host != "" {
+			allowedImageHosts = append(allowedImageHosts, host)
+		}
+	}
+
+	// Set error code settings (use defaults if not provided)
+	defaultErrorCode := cfg.DefaultErrorCode
+	if defaultErrorCode == "" {
+		defaultErrorCode = DefaultErrorCode
+	}
+
+	// Set conversation history limits (use defaults if not provided)
+	maxHistoryTurns := cfg.MaxHistoryTurns
+	if maxHistoryTurns <= 0 {
+		maxHistoryTurns = DefaultMaxHistoryTurns
+	}
+	maxHistoryChars := cfg.MaxHistoryChars
+	if maxHistoryChars <= 0 {
+		maxHistoryChars = DefaultMaxHistoryChars
+	}
+
+	// Set metrics observer (discard events if not provided)
+	metricsObserver := cfg.MetricsObserver
+	if metricsObserver == nil {
+		metricsObserver = noopMetricsObserver{}
+	}
+	metrics := cfg.Metrics
+	if metrics == nil {
+		metrics = noopMetrics{}
+	}
 
 	log.Printf("Groq client initialized successfully with model: %s", model)
 
 	return &Client{
-		client:        client,
-		model:         model,
-		promptBuilder: promptBuilder,
-		blockedTerms:  blockedTerms,
+		client:          client,
+		model:           model,
+		promptBuilder:   promptBuilder,
+		promptTemplates: normalizePromptTemplates(cfg.PromptTemplates),
+		blockedTerms:    blockedTerms,
+		termRegexps:     regexps,
+		appendedTerms:   cfg.AppendBlockedTerms,
+		appendedRegexps: compileTermRegexps(cfg.AppendBlockedTerms),
+
+		blockedPatterns: blockedPatterns,
+
+		usernamePromptBuilder: usernamePromptBuilder,
+		reservedUsernames:     reservedUsernames,
+		usernameMinLength:     usernameMinLength,
+		usernameMaxLength:     usernameMaxLength,
+
+		responseFields: cfg.ResponseFields.withDefaults(),
+		categoryCodes:  cfg.CategoryCodes,
+
+		budget: newBudgetTracker(cfg.DailyCallBudget, cfg.DailyTokenBudget, cfg.BudgetLocation),
+		cache:  newResultCache(cfg.CacheTTL, cfg.CacheSize),
+
+		requestCustomizer: cfg.RequestCustomizer,
+		usageCallback:     cfg.UsageCallback,
+
+		decodeObfuscated: cfg.DecodeObfuscatedContent,
+		decodeMinLength:  cfg.DecodeMinLength,
+
+		nonBlockingCodes: newCodeSet(cfg.NonBlockingCodes),
+
+		metricsObserver: metricsObserver,
+		metrics:         metrics,
+
+		maxInputChars: cfg.MaxInputChars,
+
+		reasonVerbosity: cfg.ReasonVerbosity,
+
+		blockedDomains: normalizeDomains(cfg.BlockedDomains),
+		allowedDomains: normalizeDomains(cfg.AllowedDomains),
+
+		localeInstruction: localeInstruction,
+
+		appealPromptBuilder: appealPromptBuilder,
+		appealModel:         appealModel,
+
+		contextPromptTemplate: cfg.ContextPromptTemplate,
+		maxHistoryTurns:       maxHistoryTurns,
+		maxHistoryChars:       maxHistoryChars,
+
+		visionModel:       visionModel,
+		allowedImageHosts: allowedImageHosts,
+
+		errorCodeMapper:  cfg.ErrorCodeMapper,
+		defaultErrorCode: defaultErrorCode,
+
+		jsonMode: cfg.JSONMode,
+
+		auditOnly:  cfg.AuditOnly,
+		auditSaver: cfg.AuditSaver,
+
+		saver: cfg.MaliciousMessageSaver,
+
+		rejectWhitespaceOnly: cfg.RejectWhitespaceOnly,
+
+		maxRetries:     cfg.MaxRetries,
+		retryBaseDelay: cfg.RetryBaseDelay,
+
+		batchConcurrency: cfg.BatchConcurrency,
+
+		requestTimeout:      cfg.RequestTimeout,
+		failClosedOnTimeout: cfg.FailClosedOnTimeout,
+
+		normalizeEvasion:       cfg.NormalizeEvasion,
+		allowedTerms:           compileAllowedTerms(cfg.AllowedTerms),
+		normalizedAllowedTerms: compileNormalizedAllowedTerms(cfg.AllowedTerms),
+		skipBlockedTerms:       cfg.SkipBlockedTerms,
+	}
+}
+
+// getEnv reads an environment variable trimming whitespace and surrounding quotes,
+// which .env files and deployment manifests often leave in (e.g., "\"llama-3.1-8b-instant\" ")
+func getEnv(key string) string {
+	value := strings.TrimSpace(os.Getenv(key))
+	if len(value) >= 2 {
+		first, last := value[0], value[len(value)-1]
+		if (first == '"' || first == '\'') && first == last {
+			value = strings.TrimSpace(value[1 : len(value)-1])
+		}
 	}
+	return value
+}
+
+// newCodeSet builds a case-insensitive set of error codes
+func newCodeSet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, code := range codes {
+		set[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	return set
 }
 
 // defaultPromptTemplate returns the default prompt template for content moderation
 func defaultPromptTemplate(messageText string) string {
-	return fmt.Sprintf(`Analyze the following message and determine if it contains malicious, inappropriate, spam, or harmful content.
+	return defaultPromptTemplateWithVerbosity(ReasonVerbosityTerse, ResponseFieldNames{}.withDefaults())(messageText)
+}
 
-Message: "%s"
+// defaultPromptTemplateWithVerbosity returns the default prompt template asking for
+// a reason of the given verbosity (or no reason at all for ReasonVerbosityNone), with
+// the JSON format using the configured response field names
+func defaultPromptTemplateWithVerbosity(verbosity ReasonVerbosity, fields ResponseFieldNames) PromptTemplate {
+	return func(messageText string) string {
+		return fmt.Sprintf(defaultPromptFormat, messageText, fields.IsMalicious, fields.ErrorCode,
+			fields.Severity, fields.Confidence, verbosity.promptReasonField(fields.Reason))
+	}
+}
+
+// defaultPromptFormat is the default moderation prompt, formatted with the message,
+// the verdict, error code, severity and confidence field names and the reason field line
+const defaultPromptFormat = `Analyze the following message and determine if it contains malicious, inappropriate, spam, or harmful content.
+
+Message: "%[1]s"
 
 Respond with ONLY a JSON object in this exact format:
 {
-  "is_malicious": true or false,
-  "error_code": "ERROR_CODE" or null,
-  "reason": "brief reason"
+  "%[2]s": true or false,
+  "%[3]s": "ERROR_CODE" or null,
+  "%[4]s": "low", "medium" or "high" if malicious, or null,
+  "%[5]s": number from 0 to 1 (how sure you are of the verdict)%[6]s
 }
 
 Error codes to use if malicious:
@@ -113,7 +581,16 @@ Error codes to use if malicious:
 - CONTENT_VIOLENCE: for violent or threatening content
 - CONTENT_OTHER: for other malicious content
 
-If the message is safe, set is_malicious to false and error_code to null.`, messageText)
+Severity: "high" for threats, slurs, hate or scams; "medium" for clearly inappropriate content; "low" for mild or probable spam.
+
+If the message is safe, set %[2]s to false and %[3]s and %[4]s to null.`
+
+// mapErrorCode translates a non-empty error code with the configured ErrorCodeMapper
+func (c *Client) mapErrorCode(code string) string {
+	if c == nil || c.errorCodeMapper == nil || code == "" {
+		return code
+	}
+	return c.errorCodeMapper(code)
 }
 
 // GetModel returns the configured model name
@@ -0,0 +1,74 @@
+package groq
+
+import (
+	"regexp"
+)
+
+// contactRedaction is the placeholder that replaces redacted contact information
+const contactRedaction = "[redacted]"
+
+// Phone numbers have between minPhoneDigits and maxPhoneDigits digits (E.164)
+const (
+	minPhoneDigits = 9
+	maxPhoneDigits = 15
+)
+
+var (
+	// emailPattern matches email addresses
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// phonePattern requires a phone-like shape: a +country code followed by digit
+	// groups, or a 3-3-4 grouping like "(601) 555-1234" or "300 123 4567". Dots are
+	// not separators, so amounts ("1.500.000") and dates are not flagged
+	phonePattern = regexp.MustCompile(`\+\d{1,3}[ -]?(?:\(\d{1,4}\)[ -]?)?\d{1,4}(?:[ -]?\d{2,4}){1,4}\b|(?:\(\d{3}\)|\b\d{3})[ -]?\d{3}[ -]?\d{4}\b`)
+)
+
+// PhoneNumberPattern returns the phone number pattern used by ContainsContactInfo and
+// RedactContactInfo, so other packages (e.g., the no_pii validator) flag the same
+// numbers. Confirm each match with IsPhoneNumber
+func PhoneNumberPattern() *regexp.Regexp {
+	return phonePattern
+}
+
+// ContainsContactInfo checks if the text shares an email address or a phone number
+func ContainsContactInfo(text string) bool {
+	if emailPattern.MatchString(text) {
+		return true
+	}
+	for _, match := range phonePattern.FindAllString(text, -1) {
+		if IsPhoneNumber(match) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactContactInfo replaces email addresses and phone numbers in the text with
+// "[redacted]", using the same patterns as ContainsContactInfo
+// This allows a message through with the contact information masked instead of
+// blocking it. Returns the redacted text and whether anything was replaced
+func RedactContactInfo(text string) (redacted string, found bool) {
+	redacted = emailPattern.ReplaceAllStringFunc(text, func(string) string {
+		found = true
+		return contactRedaction
+	})
+	redacted = phonePattern.ReplaceAllStringFunc(redacted, func(match string) string {
+		if !IsPhoneNumber(match) {
+			return match
+		}
+		found = true
+		return contactRedaction
+	})
+	return redacted, found
+}
+
+// IsPhoneNumber checks that a PhoneNumberPattern match has 9 to 15 digits (E.164),
+// so short codes are not treated as phones
+func IsPhoneNumber(match string) bool {
+	digits := 0
+	for _, r := range match {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= minPhoneDigits && digits <= maxPhoneDigits
+}
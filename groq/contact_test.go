@@ -0,0 +1,55 @@
+package groq
+
+import "testing"
+
+func TestContainsContactInfo(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"email", "escríbeme a juan@mail.com", true},
+		{"international phone", "llámame al +57 300 123 4567", true},
+		{"grouped phone", "mi celular es 300 123 4567", true},
+		{"phone with area code", "oficina (601) 555-1234", true},
+		{"salary amount", "Salario $1.500.000 COP", false},
+		{"salary range", "Salario: 1.500.000 - 2.000.000", false},
+		{"year range", "Trabajé 2019 - 2024 en Bogotá", false},
+		{"year range without spaces", "Experiencia 2015-2020", false},
+		{"reference number", "Ref 12345678", false},
+		{"date and time", "Entrevista el 2024-01-15 10:30", false},
+		{"plain text", "Busco desarrollador backend", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ContainsContactInfo(tt.text); got != tt.want {
+				t.Errorf("ContainsContactInfo(%q) = %v; want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRedactContactInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		redacted string
+		found    bool
+	}{
+		{"email and phone", "escríbeme a juan@mail.com o al +57 300 123 4567", "escríbeme a [redacted] o al [redacted]", true},
+		{"salary amount kept", "Salario $1.500.000 COP", "Salario $1.500.000 COP", false},
+		{"year range kept", "Trabajé 2019 - 2024", "Trabajé 2019 - 2024", false},
+		{"reference kept", "Ref 12345678", "Ref 12345678", false},
+		{"phone next to amount", "Pago 1.500.000, dudas al 300 123 4567", "Pago 1.500.000, dudas al [redacted]", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redacted, found := RedactContactInfo(tt.text)
+			if redacted != tt.redacted || found != tt.found {
+				t.Errorf("RedactContactInfo(%q) = %q, %v; want %q, %v", tt.text, redacted, found, tt.redacted, tt.found)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultMaxHistoryTurns is the number of most recent turns sent with the message
+	DefaultMaxHistoryTurns = 5
+	// DefaultMaxHistoryChars bounds the total characters of the history sent to the model
+	DefaultMaxHistoryChars = 2000
+)
+
+// ContextPromptTemplate generates the prompt from the message and the preceding
+// conversation turns, oldest first. The history is already bounded and sanitized
+type ContextPromptTemplate func(messageText string, history []string) string
+
+// CheckMessageContentWithContext is like CheckMessageContentDetailed but includes the
+// recent conversation turns (oldest first) in the prompt, so ambiguous messages
+// ("do it or else") are judged in context. Only the last MaxHistoryTurns turns are
+// kept and older turns are dropped first when MaxHistoryChars is exceeded
+// Local checks only look at the message, and verdicts are cached per message and history
+func (c *Client) CheckMessageContentWithContext(ctx context.Context, messageText string, history []string) (ModerationResult, error) {
+	settings := c.defaultCheckSettings()
+	if c != nil {
+		settings.history = boundHistory(history, c.maxHistoryTurns, c.maxHistoryChars)
+	}
+	return c.checkDetailed(ctx, messageText, settings)
+}
+
+// contextPromptBuilder renders the bounded history with the client's ContextPromptTemplate
+// or, by default, as a preamble to the prompt that would be used without history
+func (c *Client) contextPromptBuilder(base PromptTemplate, history []string) PromptTemplate {
+	return func(messageText string) string {
+		if c.contextPromptTemplate != nil {
+			return c.contextPromptTemplate(messageText, history)
+		}
+		return historyPreamble(history) + base(messageText)
+	}
+}
+
+// boundHistory sanitizes the turns and keeps the most recent ones within the limits
+// Each turn is flattened to a single line without control characters, so a turn cannot
+// pose as a prompt section, and empty turns are dropped
+func boundHistory(history []string, maxTurns, maxChars int) []string {
+	var turns []string
+	for _, turn := range history {
+		if turn = sanitizeHistoryTurn(turn); turn != "" {
+			turns = append(turns, turn)
+		}
+	}
+	if len(turns) > maxTurns {
+		turns = turns[len(turns)-maxTurns:]
+	}
+
+	// Walk from the newest turn and drop the older ones once the budget is exceeded
+	// Only the newest turn is truncated, older partial turns add little context
+	remaining := maxChars
+	start := len(turns)
+	for start > 0 {
+		length := len([]rune(turns[start-1]))
+		if length > remaining {
+			if start == len(turns) {
+				turns[start-1] = TruncateInput(turns[start-1], remaining)
+				start--
+			}
+			break
+		}
+		remaining -= length
+		start--
+	}
+	return turns[start:]
+}
+
+// sanitizeHistoryTurn collapses whitespace and strips control characters
+func sanitizeHistoryTurn(turn string) string {
+	turn = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, turn)
+	return strings.Join(strings.Fields(turn), " ")
+}
+
+// historyCacheText is the part of the cache key identifying the history
+func historyCacheText(history []string) string {
+	return strings.Join(history, "\x1f")
+}
+
+// historyPreamble renders the conversation before the moderation prompt
+func historyPreamble(history []string) string {
+	var conversation strings.Builder
+	for i, turn := range history {
+		fmt.Fprintf(&conversation, "%d. %q\n", i+1, turn)
+	}
+	return fmt.Sprintf(`The message below is the latest turn of a conversation. Use the previous turns only as context to judge threats, harassment or other intent that is ambiguous on its own; moderate the message, not the previous turns.
+
+Previous turns (oldest first):
+%s
+`, conversation.String())
+}
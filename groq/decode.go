@@ -0,0 +1,124 @@
+package groq
+
+import (
+	"context"
+	"encoding/base64"
+	"log"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// defaultDecodeMinLength is the minimum length of an encoded substring to be decoded
+const defaultDecodeMinLength = 16
+
+var (
+	// base64Pattern matches candidate base64 (standard or URL-safe) substrings
+	base64Pattern = regexp.MustCompile(`[A-Za-z0-9+/_-]{8,}={0,2}`)
+	// linkPattern detects links in decoded content
+	linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+)
+
+// checkDecodedContent decodes base64/URL-encoded substrings of the message and
+// moderates the decoded content, merging the verdicts
+// If any decoded payload is malicious the message is flagged as CONTENT_SCAM when
+// the payload contains a link, or CONTENT_OTHER otherwise
+func (c *Client) checkDecodedContent(ctx context.Context, messageText string, result ModerationResult, settings checkSettings) ModerationResult {
+	for _, decoded := range decodeObfuscatedSegments(messageText, c.decodeMinLength) {
+		decodedResult, err := c.checkMessage(ctx, decoded, settings)
+		if err != nil {
+			log.Printf("Error checking decoded content: %v", err)
+			continue
+		}
+		if !decodedResult.IsMalicious {
+			continue
+		}
+
+		errorCode := "CONTENT_OTHER"
+		if containsUntrustedLink(decoded, c.allowedDomains) {
+			errorCode = "CONTENT_SCAM"
+		}
+		log.Printf("Message contains malicious encoded content: error_code=%s, decoded_error_code=%s", errorCode, decodedResult.ErrorCode)
+		return ModerationResult{
+			IsMalicious: true,
+			ErrorCode:   errorCode,
+			Reason:      "Message contains malicious encoded content",
+			Model:       decodedResult.Model,
+			Provider:    decodedResult.Provider,
+			Source:      decodedResult.Source,
+		}
+	}
+	return result
+}
+
+// decodeObfuscatedSegments finds base64 and percent-encoded substrings at least
+// minLength long and returns their decoded text, skipping binary results
+func decodeObfuscatedSegments(text string, minLength int) []string {
+	if minLength <= 0 {
+		minLength = defaultDecodeMinLength
+	}
+
+	var decoded []string
+	seen := make(map[string]bool)
+	add := func(value string) {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] || !isPrintableText(value) {
+			return
+		}
+		seen[value] = true
+		decoded = append(decoded, value)
+	}
+
+	// Percent-encoded segments (e.g., "%68%74%74%70...")
+	for _, field := range strings.Fields(text) {
+		if len(field) < minLength || !strings.Contains(field, "%") {
+			continue
+		}
+		if value, err := url.QueryUnescape(field); err == nil && value != field {
+			add(value)
+		}
+	}
+
+	// Base64 segments
+	for _, candidate := range base64Pattern.FindAllString(text, -1) {
+		if len(candidate) < minLength {
+			continue
+		}
+		if value, ok := decodeBase64(candidate); ok {
+			add(value)
+		}
+	}
+
+	return decoded
+}
+
+// decodeBase64 tries the standard and URL-safe alphabets, with and without padding
+func decodeBase64(candidate string) (string, bool) {
+	encodings := []*base64.Encoding{
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+		base64.URLEncoding,
+		base64.RawURLEncoding,
+	}
+	for _, encoding := range encodings {
+		if value, err := encoding.DecodeString(candidate); err == nil {
+			return string(value), true
+		}
+	}
+	return "", false
+}
+
+// isPrintableText checks that the decoded value is valid UTF-8 text and not binary data
+func isPrintableText(value string) bool {
+	if !utf8.ValidString(value) {
+		return false
+	}
+	for _, r := range value {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return false
+		}
+	}
+	return true
+}
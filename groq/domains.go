@@ -0,0 +1,110 @@
+package groq
+
+import (
+	"log"
+	"net/url"
+	"strings"
+)
+
+// trustedLinkPlaceholder replaces links to allowed domains before the AI call so
+// our own CTAs and trusted partners' links are never flagged
+const trustedLinkPlaceholder = "[trusted link]"
+
+// checkBlockedDomains checks the links of the message against the blocked domains
+// Links to allowed domains (and their subdomains) are skipped even if they also
+// match a blocked domain
+func checkBlockedDomains(messageText string, blockedDomains, allowedDomains []string) (ModerationResult, bool) {
+	if len(blockedDomains) == 0 {
+		return ModerationResult{}, false
+	}
+
+	for _, link := range linkPattern.FindAllString(messageText, -1) {
+		host := linkHost(link)
+		if host == "" || matchesDomain(host, allowedDomains) {
+			continue
+		}
+		if matchesDomain(host, blockedDomains) {
+			log.Printf("Message contains link to blocked domain: %s", host)
+			return ModerationResult{
+				IsMalicious: true,
+				ErrorCode:   "CONTENT_SCAM",
+				Reason:      "Message contains a link to a blocked domain",
+				Provider:    ProviderLocal,
+				Confidence:  1,
+				Severity:    SeverityHigh,
+				Source:      StageBlockedDomains,
+			}, true
+		}
+	}
+	return ModerationResult{}, false
+}
+
+// replaceTrustedLinks replaces links to allowed domains with a neutral placeholder
+func replaceTrustedLinks(messageText string, allowedDomains []string) string {
+	if len(allowedDomains) == 0 {
+		return messageText
+	}
+	return linkPattern.ReplaceAllStringFunc(messageText, func(link string) string {
+		if matchesDomain(linkHost(link), allowedDomains) {
+			return trustedLinkPlaceholder
+		}
+		return link
+	})
+}
+
+// containsUntrustedLink checks if the text has a link to a domain not in allowedDomains
+func containsUntrustedLink(text string, allowedDomains []string) bool {
+	for _, link := range linkPattern.FindAllString(text, -1) {
+		if !matchesDomain(linkHost(link), allowedDomains) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkHost returns the lowercase host of a link found in a message, or "" if it
+// cannot be parsed
+func linkHost(link string) string {
+	link = strings.TrimRight(link, ".,;:!?)]}'\"")
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+}
+
+// matchesDomain checks if host is one of the domains or a subdomain of one
+// ("talentpitch.co" matches "talentpitch.co" and "app.talentpitch.co" but not
+// "eviltalentpitch.co" nor "talentpitch.co.evil.com")
+func matchesDomain(host string, domains []string) bool {
+	if host == "" {
+		return false
+	}
+	for _, domain := range domains {
+		if domain == "" {
+			continue
+		}
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomains lowercases the domains and strips wildcard and dot prefixes
+// (e.g., "*.TalentPitch.co" becomes "talentpitch.co")
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		domain = strings.TrimPrefix(domain, "*")
+		domain = strings.Trim(domain, ".")
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,57 @@
+package groq
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// statusCapacityExceeded is the non-standard status code Groq returns when the
+// flex tier is over capacity
+const statusCapacityExceeded = 498
+
+// isOverloadedError reports whether the error means the model is temporarily over
+// capacity, as opposed to a problem with the request or the credentials
+func isOverloadedError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	statusCode := 0
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	if errors.As(err, &apiErr) {
+		statusCode = apiErr.HTTPStatusCode
+	} else if errors.As(err, &reqErr) {
+		statusCode = reqErr.HTTPStatusCode
+	}
+
+	switch statusCode {
+	case http.StatusServiceUnavailable, statusCapacityExceeded:
+		return true
+	}
+
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "overloaded") || strings.Contains(message, "over capacity")
+}
+
+// isRateLimitError reports whether the provider rejected the call with 429
+func isRateLimitError(err error) bool {
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// shouldFallBackToLocal reports whether the AI check failed in a way that should
+// fall back to the local (blocked terms) verdict instead of failing open or closed
+func shouldFallBackToLocal(err error) bool {
+	return isOverloadedError(err) || errors.Is(err, errBudgetExceeded)
+}
@@ -0,0 +1,91 @@
+package groq
+
+import (
+	"strings"
+	"unicode"
+)
+
+// leetReplacer undoes the common character substitutions used to evade the blocked
+// terms (e.g., "a$$hole", "n1gga", "sh!t"). "1" stands for either "i" or "l", so it
+// is replaced separately (see normalizeEvasionVariants)
+var leetReplacer = strings.NewReplacer(
+	"0", "o",
+	"3", "e",
+	"4", "a",
+	"5", "s",
+	"7", "t",
+	"$", "s",
+	"@", "a",
+	"!", "i",
+)
+
+// normalizeEvasion lowercases the text, strips zero-width and combining characters,
+// undoes leet substitutions and collapses repeated characters ("fuuuck" -> "fuck"),
+// so evasive spellings match the blocked terms normalized the same way. "1" is read
+// as "i"; normalizeEvasionVariants also tries "l"
+func normalizeEvasion(text string) string {
+	return normalizeEvasionWith(text, "i")
+}
+
+// normalizeEvasionVariants returns the normalized text reading "1" as "i" and, when
+// the text has a "1", also reading it as "l" (e.g., "1oser" -> "loser")
+func normalizeEvasionVariants(text string) []string {
+	variants := []string{normalizeEvasionWith(text, "i")}
+	if strings.Contains(text, "1") {
+		variants = append(variants, normalizeEvasionWith(text, "l"))
+	}
+	return variants
+}
+
+// normalizeEvasionWith is normalizeEvasion replacing "1" with the given letter
+func normalizeEvasionWith(text string, one string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	for _, r := range strings.ToLower(text) {
+		if isInvisibleRune(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	normalized := leetReplacer.Replace(strings.ReplaceAll(b.String(), "1", one))
+
+	b.Reset()
+	var previous rune
+	for i, r := range normalized {
+		if i > 0 && r == previous && unicode.IsLetter(r) {
+			continue
+		}
+		b.WriteRune(r)
+		previous = r
+	}
+	return b.String()
+}
+
+// normalizeEvasionTerms normalizes the literal blocked terms like normalizeEvasion,
+// so "asshole" and "a$$hole" both become "ashole". Category prefixes are kept and
+// regex terms are kept unchanged
+func normalizeEvasionTerms(terms []string) []string {
+	normalized := make([]string, 0, len(terms))
+	for _, raw := range terms {
+		raw = strings.TrimSpace(raw)
+		term := parseTermEntry(raw).term
+		if isRegexTerm(term) {
+			normalized = append(normalized, raw)
+			continue
+		}
+		prefix := raw[:len(raw)-len(term)]
+		normalized = append(normalized, prefix+normalizeEvasion(term))
+	}
+	return normalized
+}
+
+// isInvisibleRune reports whether the rune is a zero-width or combining character
+// that can be inserted inside a word without changing how it looks
+func isInvisibleRune(r rune) bool {
+	switch r {
+	case '\u200b', '\u200c', '\u200d', '\u2060', '\ufeff', '\u00ad':
+		return true
+	}
+	return unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r)
+}
@@ -0,0 +1,72 @@
+package groq
+
+import "testing"
+
+func TestNormalizeEvasion(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"dollar signs", "a$$hole", "ashole"},
+		{"exclamation mark", "sh!t", "shit"},
+		{"at sign", "b@stard", "bastard"},
+		{"zero and three", "h0m3", "home"},
+		{"one as i", "n1ce", "nice"},
+		{"zero-width space", "fu\u200bck", "fuck"},
+		{"zero-width joiner and soft hyphen", "sh\u200di\u00adt", "shit"},
+		{"combining accent", "fu\u0301ck", "fuck"},
+		{"repeated letters", "fuuuuck", "fuck"},
+		{"uppercase", "SH!T", "shit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeEvasion(tt.text); got != tt.want {
+				t.Errorf("normalizeEvasion(%q) = %q; want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEvasionVariants(t *testing.T) {
+	variants := normalizeEvasionVariants("1oser")
+	if len(variants) != 2 || variants[0] != "ioser" || variants[1] != "loser" {
+		t.Errorf("normalizeEvasionVariants(%q) = %q; want [ioser loser]", "1oser", variants)
+	}
+	if variants := normalizeEvasionVariants("loser"); len(variants) != 1 {
+		t.Errorf("normalizeEvasionVariants(%q) = %q; want a single variant", "loser", variants)
+	}
+}
+
+func TestFindBlockedTermEvasion(t *testing.T) {
+	blockedTerms := []string{"asshole", "shit", "fuck", "loser", "idiot"}
+
+	tests := []struct {
+		name      string
+		message   string
+		normalize bool
+		want      bool
+	}{
+		{"leet dollar signs", "you are an a$$hole", true, true},
+		{"leet exclamation mark", "this is sh!t", true, true},
+		{"one as i", "what an 1d1ot", true, true},
+		{"one as l", "such a 1oser", true, true},
+		{"zero-width space", "fu\u200bck off", true, true},
+		{"combining characters", "fu\u0301ck off", true, true},
+		{"repeated letters", "fuuuuck off", true, true},
+		{"mixed evasion", "SH!!!T", true, true},
+		{"clean message", "hello there", true, false},
+		{"evasion without the flag", "you are an a$$hole", false, false},
+		{"plain term without the flag", "you are an asshole", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, _, _ := findBlockedTerm(tt.message, blockedTerms, nil, termMatchOptions{normalizeEvasion: tt.normalize})
+			if found != tt.want {
+				t.Errorf("findBlockedTerm(%q) = %v; want %v", tt.message, found, tt.want)
+			}
+		})
+	}
+}
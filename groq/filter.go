@@ -2,108 +2,428 @@ package groq
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/sashabaranov/go-openai"
+	"golang.org/x/sync/singleflight"
 )
 
 // CheckMessageContent uses Groq to analyze message content and determine if it's malicious
-// First checks against a static list of blocked terms, then uses AI if no blocked terms are found
+// First checks against a static list of blocked terms, then the result cache, and
+// only uses AI if neither decided the verdict
+// If the model is overloaded or the daily budget is exhausted, the blocked-terms verdict
+// is returned without an error
 // Returns:
 //   - isMalicious: true if the message should be rejected
 //   - errorCode: error code for the rejection reason
 //   - reason: brief reason for rejection
 //   - error: any error that occurred during the check
 func (c *Client) CheckMessageContent(ctx context.Context, messageText string) (isMalicious bool, errorCode string, reason string, err error) {
-	// First, check against static blocked terms list
-	if c != nil && len(c.blockedTerms) > 0 {
-		hasBlockedTerm, foundTerm := containsBlockedTerm(messageText, c.blockedTerms)
-		if hasBlockedTerm {
-			log.Printf("Message contains blocked term: %s", foundTerm)
-			return true, "CONTENT_INAPPROPRIATE", "Message contains inappropriate language", nil
-		}
+	result, err := c.CheckMessageContentDetailed(ctx, messageText)
+	if err != nil {
+		return false, "", "", err
+	}
+
+	return result.IsMalicious, result.ErrorCode, result.Reason, nil
+}
+
+// CheckMessageContentDetailed is like CheckMessageContent but returns the full ModerationResult
+// Error codes configured as NonBlockingCodes are downgraded: IsMalicious is false but
+// ErrorCode and Reason are populated and Warning is set
+func (c *Client) CheckMessageContentDetailed(ctx context.Context, messageText string) (ModerationResult, error) {
+	return c.checkDetailed(ctx, messageText, c.defaultCheckSettings())
+}
+
+// checkDetailed runs the moderation pipeline with the given settings, including the
+// decoded content check and the non-blocking codes downgrade
+func (c *Client) checkDetailed(ctx context.Context, messageText string, settings checkSettings) (ModerationResult, error) {
+	// Empty and whitespace-only messages never reach the terms check nor the model
+	if strings.TrimSpace(messageText) == "" {
+		c.observeStage(StageEmpty)
+		return c.emptyMessageResult(messageText), nil
+	}
+
+	start := time.Now()
+	parent := ctx
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	result, err := c.checkMessage(ctx, messageText, settings)
+	if err != nil && requestTimedOut(parent, err) {
+		result, err = c.handleRequestTimeout(err)
+	}
+	if err != nil {
+		c.recordMetrics(start, result, err)
+		return ModerationResult{}, err
+	}
+
+	// Re-moderate base64/URL-encoded payloads hidden in the message
+	if !result.IsMalicious && c != nil && c.decodeObfuscated {
+		result = c.checkDecodedContent(ctx, messageText, result, settings)
+	}
+
+	result = c.finalizeResult(result, settings)
+	c.sampleForCalibration(result)
+	c.recordMetrics(start, result, nil)
+	return c.auditResult(ctx, messageText, result), nil
+}
+
+// finalizeResult downgrades non-blocking error codes to warnings, translates the error
+// code with the ErrorCodeMapper and drops the reason when ReasonVerbosityNone is configured
+func (c *Client) finalizeResult(result ModerationResult, settings checkSettings) ModerationResult {
+	if result.IsMalicious && settings.nonBlockingCodes[strings.ToUpper(result.ErrorCode)] {
+		log.Printf("Downgrading non-blocking error code to warning: %s", result.ErrorCode)
+		result.IsMalicious = false
+		result.Warning = true
+	}
+
+	result.ErrorCode = c.mapErrorCode(result.ErrorCode)
+
+	if c != nil && c.reasonVerbosity == ReasonVerbosityNone {
+		result.Reason = ""
+	}
+
+	return result
+}
+
+// checkMessage runs the moderation pipeline on the message. Each stage can
+// short-circuit the following ones:
+//  1. blocked terms: deny-first local check, no API call
+//  2. blocked patterns: regexes for pattern-based abuse, no API call
+//  3. blocked domains: links to blocked domains, skipping allowed domains, no API call
+//  4. cache: verdict of an identical message, no API call
+//  5. AI: Groq call, falling back to the local verdict if the model is unavailable
+//
+// The stage that decided the verdict is reported to the MetricsObserver
+func (c *Client) checkMessage(ctx context.Context, messageText string, settings checkSettings) (ModerationResult, error) {
+	// Stages 1-3: blocked terms, patterns and domains
+	if result, blocked := c.checkLocal(messageText, settings); blocked {
+		return result, nil
 	}
 
 	// If no blocked terms found and client is not initialized, allow the message (fail open)
 	if c == nil || c.client == nil {
 		log.Printf("Groq client not initialized, allowing message")
-		return false, "", "", nil
+		return localFailOpenResult(), nil
 	}
 
-	model := c.GetModel()
+	// Stage 4: cached verdict for identical messages
+	key := cacheKey(settings.profile + "\x00" + settings.model + "\x00" + LocaleFromContext(ctx) + "\x00" + historyCacheText(settings.history) + "\x00" + normalizeCacheText(messageText))
+	if result, ok := c.cache.get(key); ok {
+		c.observeStage(StageCache)
+		result.Source = StageCache
+		return result, nil
+	}
 
-	// Use the configured prompt template
-	prompt := c.promptBuilder(messageText)
+	// Stage 5: AI check using the configured prompt template
+	// Links to allowed domains are hidden from the model so they are never flagged, and
+	// pathologically long inputs are truncated keeping both ends
+	// Concurrent identical messages (e.g., a viral message posted by many users at
+	// once) share a single in-flight call and its result
+	promptText := replaceTrustedLinks(messageText, c.allowedDomains)
+	// Waiting callers stop as soon as their own context is done (e.g., the HTTP client
+	// disconnected). The shared call is canceled only when every waiting caller is
+	// done, so one caller giving up does not fail the others
+	promptBuilder := c.promptBuilderFor(settings, LocaleFromContext(ctx))
+	if len(settings.history) > 0 {
+		promptBuilder = c.contextPromptBuilder(promptBuilder, settings.history)
+	}
+	callCtx, release := c.joinSharedCall(key, ctx)
+	defer release()
+	call := c.inflight.DoChan(key, func() (interface{}, error) {
+		prompt := promptBuilder(TruncateInput(promptText, c.maxInputChars))
+		// Short response, longer for detailed reasons
+		return c.moderateWithModel(callCtx, c.withLocaleInstruction(callCtx, prompt), settings.model, c.reasonVerbosity.maxTokens())
+	})
+	var response singleflight.Result
+	select {
+	case response = <-call:
+	case <-ctx.Done():
+		return ModerationResult{}, ctx.Err()
+	}
+	if response.Shared {
+		log.Printf("Shared in-flight Groq moderation for identical message")
+	}
+	result, _ := response.Val.(ModerationResult)
+	err := response.Err
+	if err != nil {
+		if shouldFallBackToLocal(err) {
+			// The model is over capacity or the daily budget is exhausted: instead of
+			// failing fully open (error) or closed, fall back to the blocked-terms-only
+			// verdict. Blocked terms were already checked above without a match, so the
+			// message is allowed.
+			log.Printf("Falling back to blocked-terms-only verdict: %v", err)
+			c.observeStage(StageFallback)
+			return localFailOpenResult(), nil
+		}
+		return ModerationResult{}, err
+	}
+	c.observeStage(StageAI)
 
-	resp, err := c.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: prompt,
-				},
-			},
-			Temperature: 0.1, // Low temperature for more consistent moderation
-			MaxTokens:   150, // Short response
-		},
-	)
+	// Only confident verdicts are cached (fail-open results are skipped by the cache)
+	c.cache.set(key, result)
 
-	if err != nil {
-		log.Printf("Error calling Groq API: %v", err)
-		// Fail open - allow message if API call fails
-		return false, "", "", fmt.Errorf("error calling Groq API: %w", err)
+	return result, nil
+}
+
+// CheckMessageContentLocal checks the message against the blocked terms, patterns and
+// domains only, without calling the model. It is the verdict used when the AI is
+// unreachable, e.g., by fail-open callers that still want the worst content rejected
+func (c *Client) CheckMessageContentLocal(messageText string) ModerationResult {
+	if strings.TrimSpace(messageText) == "" {
+		return c.emptyMessageResult(messageText)
 	}
 
-	if len(resp.Choices) == 0 {
-		log.Printf("No response from Groq API")
-		return false, "", "", fmt.Errorf("no response from Groq API")
+	settings := c.defaultCheckSettings()
+	result, blocked := c.checkLocal(messageText, settings)
+	if !blocked {
+		result = ModerationResult{Provider: ProviderLocal, Source: StageFallback}
 	}
+	return c.auditResult(context.Background(), messageText, c.finalizeResult(result, settings))
+}
 
-	// Parse the JSON response
-	responseText := resp.Choices[0].Message.Content
-	log.Printf("Groq moderation response: %s", responseText)
+// checkLocal runs the local stages of the pipeline (blocked terms, patterns and
+// domains) and reports the stage that rejected the message, if any
+func (c *Client) checkLocal(messageText string, settings checkSettings) (ModerationResult, bool) {
+	// Stage 1: static blocked terms list
+	if result, blocked := checkBlockedTerms(messageText, settings.blockedTerms, settings.termRegexps, c.termMatchOptions()); blocked {
+		c.observeStage(StageBlockedTerms)
+		return result, true
+	}
 
-	// Clean the response text (remove markdown code blocks if present)
-	responseText = strings.TrimSpace(responseText)
-	if strings.HasPrefix(responseText, "```json") {
-		responseText = strings.TrimPrefix(responseText, "```json")
-		responseText = strings.TrimSuffix(responseText, "```")
-	} else if strings.HasPrefix(responseText, "```") {
-		responseText = strings.TrimPrefix(responseText, "```")
-		responseText = strings.TrimSuffix(responseText, "```")
+	if c != nil {
+		// Stage 2: blocked regex patterns
+		if result, blocked := checkBlockedPatterns(messageText, c.blockedPatterns); blocked {
+			c.observeStage(StageBlockedPatterns)
+			return result, true
+		}
+
+		// Stage 3: links to blocked domains
+		if result, blocked := checkBlockedDomains(messageText, c.blockedDomains, c.allowedDomains); blocked {
+			c.observeStage(StageBlockedDomains)
+			return result, true
+		}
 	}
-	responseText = strings.TrimSpace(responseText)
 
-	// Parse JSON response
-	var moderationResult struct {
-		IsMalicious bool   `json:"is_malicious"`
-		ErrorCode   string `json:"error_code"`
-		Reason      string `json:"reason"`
+	return ModerationResult{}, false
+}
+
+// checkBlockedTerms checks the message against the blocked terms list
+// Allowlisted phrases are skipped and, with NormalizeEvasion, evasive spellings are
+// also checked
+func checkBlockedTerms(messageText string, blockedTerms []string, regexps termRegexps, opts termMatchOptions) (ModerationResult, bool) {
+	if len(blockedTerms) == 0 {
+		return ModerationResult{}, false
 	}
 
-	if err := json.Unmarshal([]byte(responseText), &moderationResult); err != nil {
+	hasBlockedTerm, foundTerm, errorCode := findBlockedTerm(messageText, blockedTerms, regexps, opts)
+	if !hasBlockedTerm {
+		return ModerationResult{}, false
+	}
+
+	log.Printf("Message contains blocked term: %s", foundTerm)
+	return ModerationResult{
+		IsMalicious: true,
+		ErrorCode:   errorCode,
+		Reason:      fmt.Sprintf("Message contains blocked term: %s", foundTerm),
+		Provider:    ProviderLocal,
+		Confidence:  1,
+		Severity:    SeverityHigh,
+		Source:      StageBlockedTerms,
+	}, true
+}
+
+// findBlockedTerm is containsBlockedTerm with the allowed terms masked and, if enabled,
+// a second pass over the evasion-normalized message
+func findBlockedTerm(messageText string, blockedTerms []string, regexps termRegexps, opts termMatchOptions) (bool, string, string) {
+	messageText = maskAllowedTerms(messageText, opts.allowedTerms)
+	found, term, code := containsBlockedTerm(messageText, blockedTerms, regexps)
+	if !found && opts.normalizeEvasion {
+		normalizedTerms := normalizeEvasionTerms(blockedTerms)
+		for _, variant := range normalizeEvasionVariants(messageText) {
+			variant = maskAllowedTerms(variant, opts.normalizedAllowedTerms)
+			if found, term, code = containsBlockedTerm(variant, normalizedTerms, regexps); found {
+				break
+			}
+		}
+	}
+	return found, term, code
+}
+
+// emptyMessageResult is the verdict for an empty or whitespace-only message: allowed,
+// like the validator does, unless RejectWhitespaceOnly is set and the message is not empty
+func (c *Client) emptyMessageResult(messageText string) ModerationResult {
+	if messageText != "" && c != nil && c.rejectWhitespaceOnly {
+		return ModerationResult{
+			IsMalicious: true,
+			ErrorCode:   "CONTENT_EMPTY",
+			Reason:      "Message is empty",
+			Provider:    ProviderLocal,
+			Source:      StageEmpty,
+		}
+	}
+	return ModerationResult{Provider: ProviderLocal, Source: StageEmpty}
+}
+
+// localFailOpenResult is the verdict used when the AI could not decide: the message
+// passed the local checks and is allowed
+func localFailOpenResult() ModerationResult {
+	return ModerationResult{
+		Provider: ProviderLocal,
+		Source:   StageFallback,
+		failOpen: true,
+	}
+}
+
+// moderateWithAI sends the given prompt to Groq and parses the moderation verdict
+// from the JSON response. Parse failures fail open and are not reported as errors.
+func (c *Client) moderateWithAI(ctx context.Context, prompt string) (ModerationResult, error) {
+	// Short response, longer for detailed reasons
+	return c.moderateWithModel(ctx, prompt, c.GetModel(), c.reasonVerbosity.maxTokens())
+}
+
+// moderateWithModel is like moderateWithAI but with the given model and token limit
+func (c *Client) moderateWithModel(ctx context.Context, prompt string, model string, maxTokens int) (ModerationResult, error) {
+	responseText, err := c.completeModeration(ctx, prompt, model, maxTokens)
+	if err != nil {
+		return ModerationResult{}, err
+	}
+	return c.moderationResultFromResponse(responseText, model), nil
+}
+
+// moderationResultFromResponse parses the model verdict, failing open if the response
+// cannot be parsed and has no malicious indicators
+func (c *Client) moderationResultFromResponse(responseText string, model string) ModerationResult {
+	// Parse JSON response using the configured field names
+	moderationResult, err := c.parseModerationResponse(responseText)
+	moderationResult.Model = model
+	moderationResult.Provider = ProviderGroq
+	moderationResult.Source = StageAI
+	if err != nil {
 		log.Printf("Error parsing Groq JSON response: %v, response: %s", err, responseText)
 		// If we can't parse, do a simple check for malicious indicators
-		if strings.Contains(strings.ToLower(responseText), "is_malicious") && strings.Contains(strings.ToLower(responseText), "true") {
-			return true, "CONTENT_OTHER", "", nil
+		if strings.Contains(strings.ToLower(responseText), strings.ToLower(c.responseFields.IsMalicious)) && strings.Contains(strings.ToLower(responseText), "true") {
+			moderationResult.IsMalicious = true
+			moderationResult.ErrorCode = c.defaultErrorCode
+			return moderationResult
 		}
 		// Fail open - allow message if we can't parse
-		return false, "", "", nil
+		moderationResult.failOpen = true
+		return moderationResult
 	}
 
 	if moderationResult.IsMalicious {
-		errorCode := moderationResult.ErrorCode
-		if errorCode == "" {
-			errorCode = "CONTENT_OTHER"
+		if moderationResult.ErrorCode == "" {
+			moderationResult.ErrorCode = c.defaultErrorCode
+		}
+		log.Printf("Message flagged as malicious: error_code=%s, reason=%s", moderationResult.ErrorCode, moderationResult.Reason)
+	}
+
+	return moderationResult
+}
+
+// completeModeration sends the prompt to Groq and returns the response text with
+// markdown code fences removed
+func (c *Client) completeModeration(ctx context.Context, prompt string, model string, maxTokens int) (string, error) {
+	return c.completeModerationMessage(ctx, openai.ChatCompletionMessage{
+		Role:    openai.ChatMessageRoleUser,
+		Content: prompt,
+	}, model, maxTokens)
+}
+
+// completeModerationMessage is like completeModeration for a prebuilt user message,
+// e.g., one with image parts
+func (c *Client) completeModerationMessage(ctx context.Context, message openai.ChatCompletionMessage, model string, maxTokens int) (string, error) {
+	if !c.budget.reserveCall() {
+		return "", errBudgetExceeded
+	}
+
+	request := openai.ChatCompletionRequest{
+		Model:       model,
+		Messages:    []openai.ChatCompletionMessage{message},
+		Temperature: 0.1, // Low temperature for more consistent moderation
+		MaxTokens:   maxTokens,
+	}
+	c.applyJSONMode(&request)
+
+	// Let power users tweak the request after the defaults are set
+	if c.requestCustomizer != nil {
+		c.requestCustomizer(&request)
+	}
+
+	// Transient errors (rate limits, 5xx, over capacity) are retried with exponential
+	// backoff before giving up
+	var resp openai.ChatCompletionResponse
+	var err error
+	retryAfter := &retryAfterHolder{}
+	for attempt := 0; ; attempt++ {
+		retryAfter.value = ""
+		resp, err = c.client.CreateChatCompletion(context.WithValue(ctx, retryAfterKey{}, retryAfter), request)
+		if err == nil || attempt >= c.maxRetries || !isRetryableError(err) {
+			break
+		}
+
+		delay := c.retryDelay(attempt, parseRetryAfter(retryAfter.value, err.Error()))
+		log.Printf("Retrying Groq API call in %s (attempt %d/%d): %v", delay, attempt+1, c.maxRetries, err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", fmt.Errorf("error calling Groq API: %w", ctx.Err())
 		}
-		log.Printf("Message flagged as malicious: error_code=%s, reason=%s", errorCode, moderationResult.Reason)
-		return true, errorCode, moderationResult.Reason, nil
 	}
 
-	return false, "", "", nil
+	// Models without JSON mode reject response_format, retry once without it
+	if err != nil && request.ResponseFormat != nil && isJSONModeUnsupportedError(err) {
+		c.disableJSONMode(request.Model)
+		request.ResponseFormat = nil
+		retryAfter.value = ""
+		resp, err = c.client.CreateChatCompletion(context.WithValue(ctx, retryAfterKey{}, retryAfter), request)
+	}
+
+	if err != nil {
+		log.Printf("Error calling Groq API: %v", err)
+		if isRateLimitError(err) {
+			return "", &RateLimitError{
+				RetryAfter: parseRetryAfter(retryAfter.value, err.Error()),
+				Err:        fmt.Errorf("error calling Groq API: %w", MapGroqError(err)),
+			}
+		}
+		// Retries are exhausted: return the error, callers decide whether to fall back
+		// to the local verdict (see shouldFallBackToLocal)
+		return "", fmt.Errorf("error calling Groq API: %w", MapGroqError(err))
+	}
+
+	c.budget.addTokens(resp.Usage.TotalTokens)
+	if c.usageCallback != nil {
+		c.usageCallback(resp.Usage.PromptTokens, resp.Usage.CompletionTokens, request.Model)
+	}
+
+	if len(resp.Choices) == 0 {
+		log.Printf("No response from Groq API")
+		return "", fmt.Errorf("no response from Groq API")
+	}
+
+	responseText := resp.Choices[0].Message.Content
+	log.Printf("Groq moderation response: %s", responseText)
+
+	// In JSON mode the response is a bare JSON object
+	responseText = strings.TrimSpace(responseText)
+	if request.ResponseFormat != nil {
+		return responseText, nil
+	}
+
+	// Clean the response text (remove markdown code blocks if present)
+	if strings.HasPrefix(responseText, "```json") {
+		responseText = strings.TrimPrefix(responseText, "```json")
+		responseText = strings.TrimSuffix(responseText, "```")
+	} else if strings.HasPrefix(responseText, "```") {
+		responseText = strings.TrimPrefix(responseText, "```")
+		responseText = strings.TrimSuffix(responseText, "```")
+	}
+	return strings.TrimSpace(responseText), nil
 }
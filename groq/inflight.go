@@ -0,0 +1,64 @@
+package groq
+
+import (
+	"context"
+	"sync"
+)
+
+// sharedCall is the context of an in-flight Groq call shared by the callers moderating
+// an identical message, counting the callers still waiting for its result
+type sharedCall struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+}
+
+// sharedCalls tracks the shared contexts of the in-flight calls by cache key
+type sharedCalls struct {
+	mu    sync.Mutex
+	calls map[string]*sharedCall
+}
+
+// joinSharedCall returns the context of the shared call for key, creating it from ctx
+// (keeping its values but not its cancellation) for the first caller. The release
+// function must be called once the caller stops waiting: the shared context is
+// canceled when the last waiter leaves, so the Groq call stops only when every caller
+// has disconnected or timed out
+func (c *Client) joinSharedCall(key string, ctx context.Context) (context.Context, func()) {
+	c.sharedCalls.mu.Lock()
+	defer c.sharedCalls.mu.Unlock()
+
+	if c.sharedCalls.calls == nil {
+		c.sharedCalls.calls = make(map[string]*sharedCall)
+	}
+	call, ok := c.sharedCalls.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &sharedCall{ctx: callCtx, cancel: cancel}
+		c.sharedCalls.calls[key] = call
+	}
+	call.waiters++
+
+	var once sync.Once
+	return call.ctx, func() {
+		once.Do(func() { c.leaveSharedCall(key, call) })
+	}
+}
+
+// leaveSharedCall removes a waiter from the shared call, canceling it when it was the
+// last one. The singleflight key is forgotten too, so a new caller starts a fresh call
+// instead of joining the canceled one
+func (c *Client) leaveSharedCall(key string, call *sharedCall) {
+	c.sharedCalls.mu.Lock()
+	defer c.sharedCalls.mu.Unlock()
+
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if c.sharedCalls.calls[key] == call {
+		delete(c.sharedCalls.calls, key)
+		c.inflight.Forget(key)
+	}
+}
@@ -0,0 +1,41 @@
+package groq
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// applyJSONMode forces a JSON object response when JSONMode is enabled, unless the
+// model already rejected response_format
+func (c *Client) applyJSONMode(request *openai.ChatCompletionRequest) {
+	if !c.jsonMode {
+		return
+	}
+	if _, unsupported := c.jsonModeUnsupported.Load(request.Model); unsupported {
+		return
+	}
+	request.ResponseFormat = &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+	}
+}
+
+// disableJSONMode remembers that the model does not accept response_format, so the
+// following calls are sent without it instead of failing first
+func (c *Client) disableJSONMode(model string) {
+	log.Printf("Model %s does not support JSON mode, retrying without response_format", model)
+	c.jsonModeUnsupported.Store(model, true)
+}
+
+// isJSONModeUnsupportedError detects the bad request returned by models that do not
+// accept response_format
+func isJSONModeUnsupportedError(err error) bool {
+	statusCode, _ := providerErrorDetails(err)
+	if statusCode != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "response_format") || strings.Contains(message, "json mode") || strings.Contains(message, "json_object")
+}
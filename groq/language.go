@@ -0,0 +1,55 @@
+package groq
+
+import (
+	"context"
+	"strings"
+)
+
+// CheckMessageContentLang is like CheckMessageContentDetailed for callers that
+// already know the language of the message (e.g., "es", "pt-BR"). The matching
+// PromptTemplates entry is used and the locale is passed to the model as with WithLocale
+func (c *Client) CheckMessageContentLang(ctx context.Context, messageText string, lang string) (ModerationResult, error) {
+	if lang != "" {
+		ctx = WithLocale(ctx, lang)
+	}
+	return c.CheckMessageContentDetailed(ctx, messageText)
+}
+
+// normalizeLanguage lowercases a language code and uses "-" as separator ("pt_BR" -> "pt-br")
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(lang), "_", "-"))
+}
+
+// normalizePromptTemplates returns the templates keyed by normalized language code
+func normalizePromptTemplates(templates map[string]PromptTemplate) map[string]PromptTemplate {
+	if len(templates) == 0 {
+		return nil
+	}
+	normalized := make(map[string]PromptTemplate, len(templates))
+	for lang, template := range templates {
+		if template != nil {
+			normalized[normalizeLanguage(lang)] = template
+		}
+	}
+	return normalized
+}
+
+// promptBuilderFor returns the prompt template for the locale: the PromptTemplates
+// entry of the full locale ("es-mx") or of its language ("es"), falling back to the
+// settings template. Profiles with their own template always use it
+func (c *Client) promptBuilderFor(settings checkSettings, locale string) PromptTemplate {
+	if settings.profilePrompt || locale == "" || len(c.promptTemplates) == 0 {
+		return settings.promptBuilder
+	}
+
+	lang := normalizeLanguage(locale)
+	if template, ok := c.promptTemplates[lang]; ok {
+		return template
+	}
+	if base, _, found := strings.Cut(lang, "-"); found {
+		if template, ok := c.promptTemplates[base]; ok {
+			return template
+		}
+	}
+	return settings.promptBuilder
+}
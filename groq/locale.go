@@ -0,0 +1,64 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// LocaleInstruction builds the prompt instruction telling the model the expected
+// language and region of the message (e.g., "es-MX")
+type LocaleInstruction func(locale string) string
+
+// localeKey is the context key of the message locale
+type localeKey struct{}
+
+// WithLocale returns a copy of ctx carrying the locale of the message (e.g., "es-MX")
+// Checks using that context tell the model the expected language and region, since
+// slang and acceptable-language norms vary by locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, strings.TrimSpace(locale))
+}
+
+// LocaleFromContext returns the locale set with WithLocale, or "" if none
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// localeNames are readable names for the locales of our userbase
+var localeNames = map[string]string{
+	"es":    "Spanish",
+	"es-co": "Colombian Spanish",
+	"es-mx": "Mexican Spanish",
+	"es-es": "Spanish from Spain",
+	"es-ar": "Argentinian Spanish",
+	"es-cl": "Chilean Spanish",
+	"es-pe": "Peruvian Spanish",
+	"en":    "English",
+	"en-us": "American English",
+	"en-gb": "British English",
+	"pt":    "Portuguese",
+	"pt-br": "Brazilian Portuguese",
+}
+
+// defaultLocaleInstruction tells the model the language and region of the message
+func defaultLocaleInstruction(locale string) string {
+	name, ok := localeNames[strings.ToLower(strings.ReplaceAll(locale, "_", "-"))]
+	if !ok {
+		name = fmt.Sprintf("the language of locale %q", locale)
+	}
+	return fmt.Sprintf("The message is in %s; judge slang and acceptable language according to the norms of that language and region.", name)
+}
+
+// withLocaleInstruction appends the locale instruction of the context to the prompt
+func (c *Client) withLocaleInstruction(ctx context.Context, prompt string) string {
+	locale := LocaleFromContext(ctx)
+	if locale == "" {
+		return prompt
+	}
+	return prompt + "\n\n" + c.localeInstruction(locale)
+}
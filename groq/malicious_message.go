@@ -1,5 +1,15 @@
 package groq
 
+import (
+	"context"
+	"log"
+	"strconv"
+	"time"
+)
+
+// SavedMessageTimeFormat is the format of the currentTime passed to MaliciousMessageSaver
+const SavedMessageTimeFormat = "2006-01-02 15:04:05"
+
 // MaliciousMessageSaver is an interface that must be implemented by projects
 // that want to save malicious messages to their own database
 type MaliciousMessageSaver interface {
@@ -16,6 +26,61 @@ type MaliciousMessageSaver interface {
 	SaveMaliciousMessage(fromUserID int, toUserID int, messageText string, errorCode string, reason string, currentTime string) error
 }
 
+// MaliciousMessageSaverV2 is implemented by savers that receive the rejected message
+// as a struct, so new fields (e.g., Metadata) do not break implementers. Savers
+// implementing it are called with SaveMaliciousMessageV2 by the client; the
+// positional SaveMaliciousMessage is still required for existing callers
+type MaliciousMessageSaverV2 interface {
+	MaliciousMessageSaver
+	// SaveMaliciousMessageV2 saves a rejected message to the database
+	SaveMaliciousMessageV2(msg MaliciousMessage) error
+}
+
+// SaveMaliciousMessageV2 saves the message with the saver, using SaveMaliciousMessageV2
+// if the saver implements MaliciousMessageSaverV2 and the positional method otherwise
+// CurrentTime is derived from DetectedAt (now if zero) when empty
+func SaveMaliciousMessageV2(saver MaliciousMessageSaver, msg MaliciousMessage) error {
+	if saver == nil {
+		return nil // No saver provided, skip saving
+	}
+	if msg.DetectedAt.IsZero() {
+		msg.DetectedAt = time.Now()
+	}
+	if msg.CurrentTime == "" {
+		msg.CurrentTime = msg.DetectedAt.Format(SavedMessageTimeFormat)
+	}
+
+	if saverV2, ok := saver.(MaliciousMessageSaverV2); ok {
+		return saverV2.SaveMaliciousMessageV2(msg)
+	}
+	return saver.SaveMaliciousMessage(msg.FromUserID, msg.ToUserID, msg.MessageText, msg.ErrorCode, msg.Reason, msg.CurrentTime)
+}
+
+// newMaliciousMessage builds the persisted message of a rejection, with the verdict
+// details as metadata
+func newMaliciousMessage(fromUserID int, toUserID int, messageText string, result ModerationResult) MaliciousMessage {
+	metadata := map[string]string{"source": string(result.Source)}
+	if result.Confidence > 0 {
+		metadata["confidence"] = strconv.FormatFloat(result.Confidence, 'f', 2, 64)
+	}
+	if result.Severity != "" {
+		metadata["severity"] = string(result.Severity)
+	}
+	if result.Model != "" {
+		metadata["model"] = result.Model
+	}
+
+	return MaliciousMessage{
+		FromUserID:  fromUserID,
+		ToUserID:    toUserID,
+		MessageText: messageText,
+		ErrorCode:   result.ErrorCode,
+		Reason:      result.Reason,
+		DetectedAt:  time.Now(),
+		Metadata:    metadata,
+	}
+}
+
 // SaveMaliciousMessage is a convenience function that uses the provided saver
 // to save a malicious message. This allows projects to implement their own
 // database logic while using the shared filtering functionality.
@@ -26,3 +91,22 @@ func SaveMaliciousMessage(saver MaliciousMessageSaver, fromUserID int, toUserID
 	return saver.SaveMaliciousMessage(fromUserID, toUserID, messageText, errorCode, reason, currentTime)
 }
 
+// CheckAndSave is like CheckMessageContent but persists a flagged message with the
+// configured MaliciousMessageSaver (if any), with its error code, reason and the
+// current time, so integrations cannot forget to record the rejection
+// A failed save is logged and does not change the verdict
+func (c *Client) CheckAndSave(ctx context.Context, fromUserID int, toUserID int, messageText string) (isMalicious bool, errorCode string, reason string, err error) {
+	ctx = WithMessageUsers(ctx, fromUserID, toUserID)
+	result, err := c.CheckMessageContentDetailed(ctx, messageText)
+	if err != nil {
+		return false, "", "", err
+	}
+
+	if result.IsMalicious && c != nil && c.saver != nil {
+		if err := SaveMaliciousMessageV2(c.saver, newMaliciousMessage(fromUserID, toUserID, messageText, result)); err != nil {
+			log.Printf("Error saving malicious message: %v", err)
+		}
+	}
+	return result.IsMalicious, result.ErrorCode, result.Reason, nil
+}
+
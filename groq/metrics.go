@@ -0,0 +1,81 @@
+package groq
+
+import "time"
+
+// Stage identifies the step of the moderation pipeline that decided a verdict
+type Stage string
+
+const (
+	// StageEmpty means the message was empty or whitespace-only and was not checked
+	StageEmpty Stage = "empty"
+	// StageBlockedTerms means the message matched the static blocked terms list
+	StageBlockedTerms Stage = "blocked_terms"
+	// StageBlockedPatterns means the message matched a blocked regex pattern
+	StageBlockedPatterns Stage = "blocked_patterns"
+	// StageBlockedDomains means the message links to a blocked domain
+	StageBlockedDomains Stage = "blocked_domains"
+	// StageCache means the verdict was served from the result cache
+	StageCache Stage = "cache"
+	// StageAI means the verdict required an AI call
+	StageAI Stage = "ai"
+	// StageFallback means the AI was unavailable and the local verdict was used
+	StageFallback Stage = "fallback"
+	// StageAppeal means the verdict comes from an appeal re-check (see ReCheckForAppeal)
+	StageAppeal Stage = "appeal"
+)
+
+// MetricsObserver receives moderation pipeline events, e.g., to export them to Prometheus
+// Counting the stages that decided each verdict quantifies how many API calls were saved
+type MetricsObserver interface {
+	// ObserveStage is called with the stage that decided the verdict of each checked
+	// message (decoded payloads are reported as separate checks)
+	ObserveStage(stage Stage)
+}
+
+// noopMetricsObserver is used when no MetricsObserver is configured
+type noopMetricsObserver struct{}
+
+func (noopMetricsObserver) ObserveStage(Stage) {}
+
+// Metrics receives moderation counters and latencies, e.g., to export them as
+// Prometheus counters and histograms without re-instrumenting each service
+// The number of checked messages is the count of ObserveLatency observations
+type Metrics interface {
+	// IncBlocked is called for each rejected message with the stage that rejected it
+	// (e.g., "blocked_terms" or "ai"), a low-cardinality label
+	IncBlocked(reason string)
+	// ObserveLatency is called with the duration of each check, including local ones
+	ObserveLatency(d time.Duration)
+	// IncError is called when the model could not be used: the check failed or fell
+	// back to the local verdict
+	IncError()
+}
+
+// noopMetrics is used when no Metrics is configured
+type noopMetrics struct{}
+
+func (noopMetrics) IncBlocked(string)            {}
+func (noopMetrics) ObserveLatency(time.Duration) {}
+func (noopMetrics) IncError()                    {}
+
+// recordMetrics reports the outcome and latency of a check to the configured Metrics
+func (c *Client) recordMetrics(start time.Time, result ModerationResult, err error) {
+	if c == nil || c.metrics == nil {
+		return
+	}
+	c.metrics.ObserveLatency(time.Since(start))
+	if err != nil || result.Source == StageFallback {
+		c.metrics.IncError()
+	}
+	if err == nil && result.IsMalicious {
+		c.metrics.IncBlocked(string(result.Source))
+	}
+}
+
+// observeStage reports the deciding stage to the configured observer
+func (c *Client) observeStage(stage Stage) {
+	if c == nil || c.metricsObserver == nil {
+		return
+	}
+	c.metricsObserver.ObserveStage(stage)
+}
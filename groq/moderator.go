@@ -0,0 +1,123 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+)
+
+// Moderator is a content moderation provider. *Client satisfies it, so other
+// providers (e.g., OpenAI's moderation endpoint) can be A/B tested or used as a
+// fallback behind the same interface
+type Moderator interface {
+	// CheckMessageContent returns whether the message should be rejected, with its
+	// error code and reason
+	CheckMessageContent(ctx context.Context, messageText string) (isMalicious bool, errorCode string, reason string, err error)
+}
+
+// LocalModerator is implemented by moderators that can check a message without
+// calling a remote provider (see Client.CheckMessageContentLocal)
+type LocalModerator interface {
+	CheckMessageContentLocal(messageText string) ModerationResult
+}
+
+// errModeratorFailedOpen reports a verdict allowed only because the provider could
+// not decide (overloaded model, request timeout, unparseable response...)
+var errModeratorFailedOpen = errors.New("moderator failed open")
+
+// FallbackModerator tries its moderators in order until one succeeds, e.g., Groq
+// first and another provider when Groq is down
+type FallbackModerator struct {
+	moderators []Moderator
+}
+
+// NewFallbackModerator creates a FallbackModerator with the moderators in the order
+// they are tried. Nil moderators are skipped, including a nil *Client (NewClient
+// returns nil when GROQ_API_KEY is not set)
+func NewFallbackModerator(moderators ...Moderator) *FallbackModerator {
+	f := &FallbackModerator{}
+	for _, moderator := range moderators {
+		if !isNilModerator(moderator) {
+			f.moderators = append(f.moderators, moderator)
+		}
+	}
+	return f
+}
+
+// isNilModerator reports whether the moderator is nil or an interface wrapping a nil
+// pointer, which would otherwise be tried and hide the following moderators
+func isNilModerator(moderator Moderator) bool {
+	if moderator == nil {
+		return true
+	}
+	value := reflect.ValueOf(moderator)
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Func, reflect.Chan, reflect.Slice, reflect.Interface:
+		return value.IsNil()
+	}
+	return false
+}
+
+// CheckMessageContent returns the verdict of the first moderator that succeeds
+// A *Client verdict allowed only because Groq could not decide (overloaded model,
+// request timeout...) counts as a failure, so the next moderator is tried; if no
+// other moderator decides, that allowed verdict is returned. If every moderator
+// fails, the errors of all of them are returned. A done context is returned right
+// away instead of trying the next moderator
+func (f *FallbackModerator) CheckMessageContent(ctx context.Context, messageText string) (isMalicious bool, errorCode string, reason string, err error) {
+	var errs []error
+	failedOpen := false
+	for i, moderator := range f.moderators {
+		isMalicious, errorCode, reason, err := checkModerator(ctx, moderator, messageText)
+		if err == nil {
+			return isMalicious, errorCode, reason, nil
+		}
+		if ctx.Err() != nil {
+			return false, "", "", err
+		}
+		log.Printf("Moderator %d failed, trying the next one: %v", i, err)
+		if errors.Is(err, errModeratorFailedOpen) {
+			failedOpen = true
+		}
+		errs = append(errs, err)
+	}
+
+	if failedOpen {
+		return false, "", "", nil
+	}
+	if len(errs) == 0 {
+		return false, "", "", fmt.Errorf("no moderators configured")
+	}
+	return false, "", "", fmt.Errorf("all moderators failed: %w", errors.Join(errs...))
+}
+
+// checkModerator runs a moderator of the chain. For a *Client the detailed result is
+// used so that fail-open verdicts are reported as errModeratorFailedOpen
+func checkModerator(ctx context.Context, moderator Moderator, messageText string) (bool, string, string, error) {
+	client, ok := moderator.(*Client)
+	if !ok {
+		return moderator.CheckMessageContent(ctx, messageText)
+	}
+
+	result, err := client.CheckMessageContentDetailed(ctx, messageText)
+	if err != nil {
+		return false, "", "", err
+	}
+	if result.failOpen {
+		return false, "", "", errModeratorFailedOpen
+	}
+	return result.IsMalicious, result.ErrorCode, result.Reason, nil
+}
+
+// CheckMessageContentLocal delegates to the first moderator able to check messages
+// locally, so fail-open callers keep the local checks. Without one the message is allowed
+func (f *FallbackModerator) CheckMessageContentLocal(messageText string) ModerationResult {
+	for _, moderator := range f.moderators {
+		if local, ok := moderator.(LocalModerator); ok {
+			return local.CheckMessageContentLocal(messageText)
+		}
+	}
+	return ModerationResult{Provider: ProviderLocal, Source: StageFallback}
+}
@@ -0,0 +1,86 @@
+package groq
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+)
+
+const (
+	// defaultPatternCode is the error code of blocked patterns without a code prefix
+	defaultPatternCode = "CONTENT_SPAM"
+	// maxPatternLength bounds the size of blocked patterns. Go regexps run in linear
+	// time, so bounding the pattern bounds the cost of each check
+	maxPatternLength = 512
+)
+
+// patternCodePrefix matches an optional "CODE:" prefix of a blocked pattern
+var patternCodePrefix = regexp.MustCompile(`^([A-Z][A-Z0-9_]*):`)
+
+// blockedPattern is a compiled blocked pattern with its error code
+type blockedPattern struct {
+	code    string
+	pattern *regexp.Regexp
+}
+
+// compileBlockedPatterns compiles the blocked patterns once. Each pattern may start
+// with an error code prefix (e.g., "CONTENT_SCAM:(?i)bit\.ly/"); without it the
+// code is CONTENT_SPAM. Invalid patterns are returned as errors and skipped
+func compileBlockedPatterns(patterns []string) ([]blockedPattern, error) {
+	var compiled []blockedPattern
+	var errs []error
+	for _, raw := range patterns {
+		code, expr := defaultPatternCode, raw
+		if match := patternCodePrefix.FindStringSubmatch(raw); match != nil {
+			code, expr = match[1], raw[len(match[0]):]
+		}
+
+		if len(expr) > maxPatternLength {
+			errs = append(errs, fmt.Errorf("blocked pattern %q exceeds %d characters", truncatePattern(expr), maxPatternLength))
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid blocked pattern %q: %w", truncatePattern(expr), err))
+			continue
+		}
+		compiled = append(compiled, blockedPattern{code: code, pattern: re})
+	}
+	return compiled, errors.Join(errs...)
+}
+
+// checkBlockedPatterns checks the message against the compiled blocked patterns
+func checkBlockedPatterns(messageText string, patterns []blockedPattern) (ModerationResult, bool) {
+	for _, p := range patterns {
+		if p.pattern.MatchString(messageText) {
+			log.Printf("Message matches blocked pattern: %s", p.pattern.String())
+			return ModerationResult{
+				IsMalicious: true,
+				ErrorCode:   p.code,
+				Reason:      "Message matches a blocked pattern",
+				Confidence:  1,
+				Severity:    SeverityMedium,
+				Provider:    ProviderLocal,
+				Source:      StageBlockedPatterns,
+			}, true
+		}
+	}
+	return ModerationResult{}, false
+}
+
+// truncatePattern shortens a pattern for error messages
+func truncatePattern(expr string) string {
+	if len(expr) > 40 {
+		return expr[:40] + "..."
+	}
+	return expr
+}
+
+// Validate checks the configuration, returning an error for each blocked pattern
+// that cannot be compiled. NewClient skips (and logs) invalid patterns, so call
+// Validate at startup to fail fast instead
+func (cfg Config) Validate() error {
+	_, err := compileBlockedPatterns(cfg.BlockedPatterns)
+	return err
+}
@@ -0,0 +1,126 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+)
+
+// ModerationProfile bundles the moderation settings for a type of field
+// (e.g., job posting titles must be clean while bodies allow frank language)
+// Nil fields fall back to the client configuration
+type ModerationProfile struct {
+	// PromptTemplate is the prompt used for this profile
+	PromptTemplate PromptTemplate
+	// BlockedTerms is the blocked terms list for this profile
+	// If empty slice is provided, blocked terms checking is disabled for this profile
+	BlockedTerms []string
+	// NonBlockingCodes are the error codes downgraded to warnings for this profile
+	NonBlockingCodes []string
+}
+
+// checkSettings holds the effective settings used for a single check
+type checkSettings struct {
+	profile          string
+	model            string
+	promptBuilder    PromptTemplate
+	profilePrompt    bool
+	history          []string
+	blockedTerms     []string
+	termRegexps      termRegexps
+	nonBlockingCodes map[string]bool
+}
+
+// registeredProfile is a ModerationProfile with its regex terms compiled at registration
+type registeredProfile struct {
+	ModerationProfile
+	termRegexps termRegexps
+}
+
+// RegisterProfile registers a moderation profile under the given name, replacing
+// any profile previously registered with the same name
+func (c *Client) RegisterProfile(name string, profile ModerationProfile) {
+	if c == nil {
+		return
+	}
+
+	registered := registeredProfile{
+		ModerationProfile: profile,
+		termRegexps:       compileTermRegexps(profile.BlockedTerms),
+	}
+
+	c.profilesMu.Lock()
+	defer c.profilesMu.Unlock()
+
+	if c.profiles == nil {
+		c.profiles = make(map[string]registeredProfile)
+	}
+	c.profiles[name] = registered
+}
+
+// CheckMessageContentWithProfile is like CheckMessageContentDetailed but uses the
+// prompt, blocked terms and non-blocking codes of the profile registered under the
+// given name. Returns an error if the profile is not registered
+func (c *Client) CheckMessageContentWithProfile(ctx context.Context, profileName string, messageText string) (ModerationResult, error) {
+	settings, err := c.profileCheckSettings(profileName)
+	if err != nil {
+		return ModerationResult{}, err
+	}
+	return c.checkDetailed(ctx, messageText, settings)
+}
+
+// CheckMessageContentWithModel is like CheckMessageContentDetailed but uses the given
+// model for this call only, without changing the client (GetModel still returns the
+// default). It allows escalating borderline messages to a stronger model
+// An empty model uses the default
+func (c *Client) CheckMessageContentWithModel(ctx context.Context, model string, messageText string) (ModerationResult, error) {
+	settings := c.defaultCheckSettings()
+	if model != "" {
+		settings.model = model
+	}
+	return c.checkDetailed(ctx, messageText, settings)
+}
+
+// defaultCheckSettings returns the settings from the client configuration
+func (c *Client) defaultCheckSettings() checkSettings {
+	if c == nil {
+		return checkSettings{}
+	}
+	settings := checkSettings{
+		model:            c.model,
+		promptBuilder:    c.getPromptBuilder(),
+		nonBlockingCodes: c.nonBlockingCodes,
+	}
+	if !c.skipBlockedTerms {
+		settings.blockedTerms, settings.termRegexps = c.getBlockedTermSet()
+	}
+	return settings
+}
+
+// profileCheckSettings returns the client settings overridden by the named profile
+func (c *Client) profileCheckSettings(name string) (checkSettings, error) {
+	if c == nil {
+		return checkSettings{}, fmt.Errorf("groq client not initialized")
+	}
+
+	c.profilesMu.RLock()
+	profile, ok := c.profiles[name]
+	c.profilesMu.RUnlock()
+	if !ok {
+		return checkSettings{}, fmt.Errorf("moderation profile %q not registered", name)
+	}
+
+	settings := c.defaultCheckSettings()
+	settings.profile = name
+	if profile.PromptTemplate != nil {
+		settings.promptBuilder = profile.PromptTemplate
+		settings.profilePrompt = true
+	}
+	if profile.BlockedTerms != nil {
+		settings.blockedTerms = profile.BlockedTerms
+		settings.termRegexps = profile.termRegexps
+	}
+	if profile.NonBlockingCodes != nil {
+		settings.nonBlockingCodes = newCodeSet(profile.NonBlockingCodes)
+	}
+	return settings, nil
+}
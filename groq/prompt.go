@@ -0,0 +1,27 @@
+package groq
+
+// SetPromptTemplate replaces the moderation prompt at runtime, e.g., from a
+// control-plane update, without recreating the client. A nil template restores the
+// default prompt. Cached verdicts are dropped so the new prompt applies right away
+// Prompts of profiles and PromptTemplates for specific languages are not changed
+func (c *Client) SetPromptTemplate(template PromptTemplate) {
+	if c == nil {
+		return
+	}
+	if template == nil {
+		template = defaultPromptTemplateWithVerbosity(c.reasonVerbosity, c.responseFields)
+	}
+
+	c.promptMu.Lock()
+	c.promptBuilder = template
+	c.promptMu.Unlock()
+
+	c.cache.clear()
+}
+
+// getPromptBuilder returns the current moderation prompt template
+func (c *Client) getPromptBuilder() PromptTemplate {
+	c.promptMu.RLock()
+	defer c.promptMu.RUnlock()
+	return c.promptBuilder
+}
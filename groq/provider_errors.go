@@ -0,0 +1,156 @@
+package groq
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Normalized provider error categories. Errors returned by the client wrap one of
+// them when the provider error could be classified, so retry and circuit-breaker
+// logic can branch on the category instead of the raw API error:
+//
+//	if errors.Is(err, groq.ErrRateLimited) { ... }
+var (
+	// ErrRateLimited means the provider rejected the call for exceeding its rate limits
+	ErrRateLimited = errors.New("provider rate limited")
+	// ErrAuth means the API key is missing, invalid or not allowed to use the model
+	ErrAuth = errors.New("provider authentication failed")
+	// ErrModelNotFound means the model does not exist or was decommissioned
+	ErrModelNotFound = errors.New("provider model not found")
+	// ErrServer means a provider-side failure (5xx, overloaded, over capacity)
+	ErrServer = errors.New("provider server error")
+)
+
+// Provider names for ProviderError
+const (
+	ProviderOpenAI    = "openai"
+	ProviderAnthropic = "anthropic"
+)
+
+// ErrorMapper classifies a raw provider error into a ProviderError, returning the
+// error unchanged if it cannot be classified
+type ErrorMapper func(err error) error
+
+// ProviderError is a provider error classified into a normalized category
+// errors.Is matches the category (e.g., ErrServer) and errors.As still reaches the
+// raw provider error (e.g., *openai.APIError)
+type ProviderError struct {
+	Provider string
+	Category error
+	Err      error
+}
+
+func (e *ProviderError) Error() string {
+	return fmt.Sprintf("%s: %v: %v", e.Provider, e.Category, e.Err)
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the category of the error
+func (e *ProviderError) Is(target error) bool {
+	return target == e.Category
+}
+
+// MapGroqError classifies Groq API errors. Groq also uses 498 for flex tier over capacity
+func MapGroqError(err error) error {
+	return mapProviderError(ProviderGroq, err, func(statusCode int, _ string, message string) error {
+		if statusCode == statusCapacityExceeded || strings.Contains(message, "over capacity") {
+			return ErrServer
+		}
+		return nil
+	})
+}
+
+// MapOpenAIError classifies OpenAI API errors, including the "model_not_found" code
+func MapOpenAIError(err error) error {
+	return mapProviderError(ProviderOpenAI, err, func(_ int, errorType string, _ string) error {
+		if errorType == "model_not_found" {
+			return ErrModelNotFound
+		}
+		return nil
+	})
+}
+
+// MapAnthropicError classifies Anthropic API errors by their error type
+// (e.g., "overloaded_error" with status 529)
+func MapAnthropicError(err error) error {
+	return mapProviderError(ProviderAnthropic, err, func(_ int, errorType string, _ string) error {
+		switch errorType {
+		case "rate_limit_error":
+			return ErrRateLimited
+		case "authentication_error", "permission_error":
+			return ErrAuth
+		case "not_found_error":
+			return ErrModelNotFound
+		case "overloaded_error", "api_error":
+			return ErrServer
+		}
+		return nil
+	})
+}
+
+// mapProviderError classifies err using the provider specific rules first and then
+// the HTTP status code. Errors already classified are returned unchanged
+func mapProviderError(provider string, err error, specific func(statusCode int, errorType string, message string) error) error {
+	if err == nil {
+		return nil
+	}
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return err
+	}
+
+	statusCode, errorType := providerErrorDetails(err)
+	message := strings.ToLower(err.Error())
+
+	category := specific(statusCode, errorType, message)
+	if category == nil {
+		category = categoryFromStatus(statusCode, message)
+	}
+	if category == nil {
+		return err
+	}
+	return &ProviderError{Provider: provider, Category: category, Err: err}
+}
+
+// providerErrorDetails extracts the HTTP status code and the error type or code
+// from go-openai errors (used for every OpenAI-compatible provider)
+func providerErrorDetails(err error) (int, string) {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		errorType := apiErr.Type
+		if code, ok := apiErr.Code.(string); ok && code != "" {
+			errorType = code
+		}
+		return apiErr.HTTPStatusCode, errorType
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode, ""
+	}
+	return 0, ""
+}
+
+// categoryFromStatus classifies the error by its HTTP status code
+func categoryFromStatus(statusCode int, message string) error {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrAuth
+	case statusCode == http.StatusNotFound:
+		return ErrModelNotFound
+	case statusCode >= http.StatusInternalServerError:
+		return ErrServer
+	case strings.Contains(message, "overloaded"):
+		return ErrServer
+	}
+	return nil
+}
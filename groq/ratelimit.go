@@ -0,0 +1,86 @@
+package groq
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimitError is returned when Groq rejects the call with 429 Too Many Requests
+// RetryAfter is the back-off hinted by the provider (0 if it did not send one), so
+// callers can back off or tighten their own inbound rate limits
+// Use errors.As to detect it:
+//
+//	var rateLimitErr *groq.RateLimitError
+//	if errors.As(err, &rateLimitErr) { ... rateLimitErr.RetryAfter ... }
+type RateLimitError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("groq rate limit exceeded, retry after %s: %v", e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("groq rate limit exceeded: %v", e.Err)
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// retryAfterMessagePattern matches the hint Groq includes in rate limit messages
+// (e.g., "Please try again in 7.66s" or "try again in 1m30s")
+var retryAfterMessagePattern = regexp.MustCompile(`(?i)try again in ((?:\d+h)?(?:\d+m)?(?:\d+(?:\.\d+)?(?:ms|s))?)`)
+
+// retryAfterKey is the context key of the retryAfterHolder of a call
+type retryAfterKey struct{}
+
+// retryAfterHolder receives the Retry-After header of a rate limited call, since
+// go-openai does not expose the response headers in its errors
+type retryAfterHolder struct {
+	value string
+}
+
+// retryAfterTransport copies the Retry-After header of 429 responses into the
+// retryAfterHolder found in the request context
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+	if holder, ok := req.Context().Value(retryAfterKey{}).(*retryAfterHolder); ok {
+		holder.value = resp.Header.Get("Retry-After")
+	}
+	return resp, err
+}
+
+// parseRetryAfter returns the back-off from the Retry-After header (seconds or HTTP
+// date) or, if missing, from the hint in the error message. Returns 0 if neither has one
+func parseRetryAfter(header string, message string) time.Duration {
+	header = strings.TrimSpace(header)
+	if header != "" {
+		if seconds, err := strconv.ParseFloat(header, 64); err == nil && seconds > 0 {
+			return time.Duration(seconds * float64(time.Second))
+		}
+		if date, err := http.ParseTime(header); err == nil {
+			if wait := time.Until(date); wait > 0 {
+				return wait
+			}
+		}
+	}
+
+	if match := retryAfterMessagePattern.FindStringSubmatch(message); match != nil && match[1] != "" {
+		if wait, err := time.ParseDuration(match[1]); err == nil {
+			return wait
+		}
+	}
+	return 0
+}
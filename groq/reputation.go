@@ -0,0 +1,115 @@
+package groq
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultReputationHalfLife is the half-life of a violation when no decay is configured
+	defaultReputationHalfLife = 7 * 24 * time.Hour
+	// defaultReputationMaxAge is the age after which violations are forgotten
+	defaultReputationMaxAge = 90 * 24 * time.Hour
+)
+
+// DecayFunc returns the weight multiplier (between 0 and 1) of a violation given its age
+type DecayFunc func(age time.Duration) float64
+
+// ExponentialDecay returns a DecayFunc that halves the weight of a violation every halfLife
+func ExponentialDecay(halfLife time.Duration) DecayFunc {
+	return func(age time.Duration) float64 {
+		if age <= 0 {
+			return 1
+		}
+		return math.Pow(0.5, float64(age)/float64(halfLife))
+	}
+}
+
+// LinearDecay returns a DecayFunc that decreases the weight linearly to 0 over window
+func LinearDecay(window time.Duration) DecayFunc {
+	return func(age time.Duration) float64 {
+		if age >= window {
+			return 0
+		}
+		if age <= 0 {
+			return 1
+		}
+		return 1 - float64(age)/float64(window)
+	}
+}
+
+// ReputationStore keeps moderation violations per user and computes an abuse score
+// where recent violations weigh more than old ones
+// The decay is computed lazily when the score is read, so no background sweeps are needed
+type ReputationStore struct {
+	mu         sync.Mutex
+	decay      DecayFunc
+	maxAge     time.Duration
+	violations map[int][]violation
+}
+
+type violation struct {
+	at     time.Time
+	weight float64
+}
+
+// NewReputationStore creates an in-memory reputation store
+// If decay is nil, ExponentialDecay with a 7 day half-life is used
+// Violations older than maxAge are dropped on read (defaults to 90 days)
+func NewReputationStore(decay DecayFunc, maxAge time.Duration) *ReputationStore {
+	if decay == nil {
+		decay = ExponentialDecay(defaultReputationHalfLife)
+	}
+	if maxAge <= 0 {
+		maxAge = defaultReputationMaxAge
+	}
+	return &ReputationStore{
+		decay:      decay,
+		maxAge:     maxAge,
+		violations: make(map[int][]violation),
+	}
+}
+
+// RecordViolation records a violation for the user with the given weight
+// (e.g., 1 for spam, 5 for a violent threat)
+func (s *ReputationStore) RecordViolation(userID int, weight float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.violations[userID] = append(s.violations[userID], violation{at: time.Now(), weight: weight})
+}
+
+// Score returns the time-decayed abuse score of the user (0 means no recent violations)
+// Violations older than maxAge are pruned while computing the score
+func (s *ReputationStore) Score(userID int) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	kept := s.violations[userID][:0]
+	score := 0.0
+	for _, v := range s.violations[userID] {
+		age := now.Sub(v.at)
+		if age > s.maxAge {
+			continue
+		}
+		kept = append(kept, v)
+		score += v.weight * s.decay(age)
+	}
+
+	if len(kept) == 0 {
+		delete(s.violations, userID)
+	} else {
+		s.violations[userID] = kept
+	}
+	return score
+}
+
+// Reset forgets all violations of the user
+func (s *ReputationStore) Reset(userID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.violations, userID)
+}
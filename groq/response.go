@@ -0,0 +1,156 @@
+package groq
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ResponseFieldNames holds the JSON field names expected in the moderation response
+// Some models output fields like "flagged" and "category" more reliably than
+// "is_malicious" and "error_code", so the names can be configured per model
+type ResponseFieldNames struct {
+	// IsMalicious is the boolean verdict field (defaults to "is_malicious")
+	IsMalicious string
+	// ErrorCode is the category/error code field (defaults to "error_code")
+	ErrorCode string
+	// Reason is the reason field (defaults to "reason")
+	Reason string
+	// Confidence is the numeric confidence field, from 0 to 1 (defaults to "confidence")
+	Confidence string
+	// Severity is the severity field, "low", "medium" or "high" (defaults to "severity")
+	Severity string
+}
+
+// withDefaults returns a copy of the field names with empty names set to the defaults
+func (f ResponseFieldNames) withDefaults() ResponseFieldNames {
+	if f.IsMalicious == "" {
+		f.IsMalicious = "is_malicious"
+	}
+	if f.ErrorCode == "" {
+		f.ErrorCode = "error_code"
+	}
+	if f.Reason == "" {
+		f.Reason = "reason"
+	}
+	if f.Confidence == "" {
+		f.Confidence = "confidence"
+	}
+	if f.Severity == "" {
+		f.Severity = "severity"
+	}
+	return f
+}
+
+// parseModerationResponse parses the cleaned JSON response using the configured
+// field names, mapping the returned category to an error code
+// JSON wrapped in prose is extracted (see unmarshalResponse)
+func (c *Client) parseModerationResponse(responseText string) (ModerationResult, error) {
+	var fields map[string]interface{}
+	if err := unmarshalResponse(responseText, &fields); err != nil {
+		return ModerationResult{}, err
+	}
+
+	// A missing verdict (renamed field, model ignoring the format) must not pass the
+	// message as safe, it goes through the unparseable response fallback instead
+	isMalicious, ok := parseBoolField(fields[c.responseFields.IsMalicious])
+	if !ok {
+		return ModerationResult{}, fmt.Errorf("missing or non-boolean %q field", c.responseFields.IsMalicious)
+	}
+
+	result := ModerationResult{
+		IsMalicious: isMalicious,
+		ErrorCode:   parseStringField(fields[c.responseFields.ErrorCode]),
+		Reason:      parseStringField(fields[c.responseFields.Reason]),
+		Confidence:  parseConfidenceField(fields[c.responseFields.Confidence]),
+		Severity:    parseSeverityField(fields[c.responseFields.Severity]),
+	}
+	if !result.IsMalicious {
+		return ModerationResult{Confidence: result.Confidence}, nil
+	}
+
+	result.ErrorCode = c.mapCategoryCode(result.ErrorCode)
+	return result, nil
+}
+
+// mapCategoryCode translates a category returned by the model into an error code
+// using the configured CategoryCodes and then the default categories of blocked
+// terms ("spam" -> CONTENT_SPAM, "harassment" -> CONTENT_HARASSMENT...), both
+// case-insensitive. Unknown categories are returned unchanged
+func (c *Client) mapCategoryCode(category string) string {
+	if category == "" {
+		return category
+	}
+	for name, code := range c.categoryCodes {
+		if strings.EqualFold(name, category) {
+			return code
+		}
+	}
+	if code, ok := termCategoryCodes[strings.ToLower(category)]; ok {
+		return code
+	}
+	return category
+}
+
+// parseBoolField accepts JSON booleans as well as "true"/"false"/"yes"/"no" strings and
+// 0/1 numbers. ok is false if the field is missing or holds anything else
+func parseBoolField(value interface{}) (verdict bool, ok bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "true", "yes":
+			return true, true
+		case "false", "no":
+			return false, true
+		}
+	case float64:
+		if v == 0 || v == 1 {
+			return v == 1, true
+		}
+	}
+	return false, false
+}
+
+// parseStringField returns the field as a string, treating null as empty
+func parseStringField(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	}
+	return fmt.Sprint(value)
+}
+
+// parseConfidenceField returns the field as a confidence between 0 and 1, accepting
+// numbers, numeric strings and percentages (e.g., 85 or "85%"). Returns 0 if missing
+func parseConfidenceField(value interface{}) float64 {
+	var confidence float64
+	switch v := value.(type) {
+	case float64:
+		confidence = v
+	case string:
+		text := strings.TrimSpace(v)
+		percent := strings.HasSuffix(text, "%")
+		parsed, err := strconv.ParseFloat(strings.TrimSuffix(text, "%"), 64)
+		if err != nil {
+			return 0
+		}
+		confidence = parsed
+		if percent {
+			confidence /= 100
+		}
+	default:
+		return 0
+	}
+
+	if confidence > 1 && confidence <= 100 {
+		confidence /= 100
+	}
+	if confidence < 0 || confidence > 1 {
+		return 0
+	}
+	return confidence
+}
@@ -0,0 +1,92 @@
+package groq
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// unmarshalResponse decodes the JSON verdict of the model. If the response is not
+// plain JSON (e.g., "Here is the result: {...}"), the first balanced {...} object
+// that decodes is used, tolerating trailing commas. The error of the plain decode is
+// returned when no object is found
+func unmarshalResponse(responseText string, v interface{}) error {
+	err := json.Unmarshal([]byte(responseText), v)
+	if err == nil {
+		return nil
+	}
+
+	for rest := responseText; ; {
+		object, next, ok := nextJSONObject(rest)
+		if !ok {
+			return err
+		}
+		if json.Unmarshal([]byte(removeTrailingCommas(object)), v) == nil {
+			return nil
+		}
+		rest = next
+	}
+}
+
+// nextJSONObject returns the first balanced {...} object in text and the text after
+// its opening brace, so nested or following objects can be tried next
+// Braces inside JSON strings are ignored
+func nextJSONObject(text string) (string, string, bool) {
+	for {
+		start := strings.IndexByte(text, '{')
+		if start < 0 {
+			return "", "", false
+		}
+
+		depth := 0
+		inString := false
+		escaped := false
+		for i := start; i < len(text); i++ {
+			ch := text[i]
+			switch {
+			case escaped:
+				escaped = false
+			case inString && ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = !inString
+			case inString:
+			case ch == '{':
+				depth++
+			case ch == '}':
+				depth--
+				if depth == 0 {
+					return text[start : i+1], text[start+1:], true
+				}
+			}
+		}
+
+		// Unbalanced from this brace, try from the next one
+		text = text[start+1:]
+	}
+}
+
+// removeTrailingCommas drops commas directly followed by a closing brace or bracket,
+// a common mistake of models writing JSON
+func removeTrailingCommas(object string) string {
+	var b strings.Builder
+	inString := false
+	escaped := false
+	for i := 0; i < len(object); i++ {
+		ch := object[i]
+		switch {
+		case escaped:
+			escaped = false
+		case inString && ch == '\\':
+			escaped = true
+		case ch == '"':
+			inString = !inString
+		case !inString && ch == ',':
+			next := strings.TrimLeft(object[i+1:], " \t\r\n")
+			if strings.HasPrefix(next, "}") || strings.HasPrefix(next, "]") {
+				continue
+			}
+		}
+		b.WriteByte(ch)
+	}
+	return b.String()
+}
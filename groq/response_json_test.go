@@ -0,0 +1,89 @@
+package groq
+
+import "testing"
+
+func TestNextJSONObject(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		object string
+		ok     bool
+	}{
+		{"plain object", `{"a": 1}`, `{"a": 1}`, true},
+		{"wrapped in prose", `Here is the result: {"a": 1} Hope it helps`, `{"a": 1}`, true},
+		{"nested object", `{"a": {"b": 2}}`, `{"a": {"b": 2}}`, true},
+		{"braces inside strings", `{"reason": "uses } and { in text"}`, `{"reason": "uses } and { in text"}`, true},
+		{"escaped quote in string", `{"reason": "said \"}\" loudly"}`, `{"reason": "said \"}\" loudly"}`, true},
+		{"unbalanced before valid", `note {broken {"a": 1}`, `{"a": 1}`, true},
+		{"first of several objects", `{"a": 1} {"b": 2}`, `{"a": 1}`, true},
+		{"no object", `no json here`, "", false},
+		{"only unbalanced", `{"a": 1`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			object, _, ok := nextJSONObject(tt.text)
+			if ok != tt.ok || object != tt.object {
+				t.Errorf("nextJSONObject(%q) = %q, %v; want %q, %v", tt.text, object, ok, tt.object, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRemoveTrailingCommas(t *testing.T) {
+	tests := []struct {
+		name   string
+		object string
+		want   string
+	}{
+		{"no trailing comma", `{"a": 1, "b": 2}`, `{"a": 1, "b": 2}`},
+		{"before closing brace", `{"a": 1,}`, `{"a": 1}`},
+		{"before closing brace with whitespace", "{\"a\": 1,\n}", "{\"a\": 1\n}"},
+		{"before closing bracket", `{"a": [1, 2,]}`, `{"a": [1, 2]}`},
+		{"inside string kept", `{"reason": "a,}"}`, `{"reason": "a,}"}`},
+		{"escaped quote in string", `{"reason": "\",}",}`, `{"reason": "\",}"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := removeTrailingCommas(tt.object); got != tt.want {
+				t.Errorf("removeTrailingCommas(%q) = %q; want %q", tt.object, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResponse(t *testing.T) {
+	type verdict struct {
+		IsMalicious bool   `json:"is_malicious"`
+		ErrorCode   string `json:"error_code"`
+	}
+
+	tests := []struct {
+		name    string
+		text    string
+		want    verdict
+		wantErr bool
+	}{
+		{"plain json", `{"is_malicious": true, "error_code": "CONTENT_SPAM"}`, verdict{true, "CONTENT_SPAM"}, false},
+		{"prose wrapped", `Sure! Here is the verdict: {"is_malicious": true, "error_code": "CONTENT_SCAM"} Let me know.`, verdict{true, "CONTENT_SCAM"}, false},
+		{"trailing comma", `{"is_malicious": true, "error_code": "CONTENT_SPAM",}`, verdict{true, "CONTENT_SPAM"}, false},
+		{"prose wrapped with trailing comma", "Result:\n{\n  \"is_malicious\": false,\n}\n", verdict{}, false},
+		{"first decodable of several blocks", `{"is_malicious": oops} then {"is_malicious": true, "error_code": "CONTENT_OTHER"}`, verdict{true, "CONTENT_OTHER"}, false},
+		{"braces in reason", `Verdict: {"is_malicious": true, "error_code": "CONTENT_SPAM", "reason": "says {free} money"}`, verdict{true, "CONTENT_SPAM"}, false},
+		{"no json", `I cannot help with that`, verdict{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got verdict
+			err := unmarshalResponse(tt.text, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unmarshalResponse(%q) error = %v; wantErr %v", tt.text, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("unmarshalResponse(%q) = %+v; want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package groq
+
+const (
+	// ProviderGroq identifies verdicts produced by the Groq API
+	ProviderGroq = "groq"
+	// ProviderLocal identifies verdicts produced locally (blocked terms, fallbacks)
+	ProviderLocal = "local"
+)
+
+// ModerationResult holds the verdict of a moderation check
+type ModerationResult struct {
+	// IsMalicious is true if the content should be rejected
+	IsMalicious bool
+	// ErrorCode is the code for the rejection reason (e.g., "CONTENT_SPAM")
+	ErrorCode string
+	// Reason is a brief reason for the rejection
+	Reason string
+	// Confidence is the model confidence in the verdict, from 0 to 1 (0 if the model did
+	// not report one; 1 for deterministic local rejections)
+	Confidence float64
+	// Severity is how harmful the content is (low, medium or high), empty if the
+	// content is allowed or the model did not report one
+	Severity Severity
+	// Warning is true when the content matched a non-blocking error code: it is allowed
+	// (IsMalicious is false) but ErrorCode and Reason are populated
+	Warning bool
+	// Audited is true when AuditOnly allowed content that would have been rejected
+	// (IsMalicious is false) and ErrorCode and Reason are those of the rejection
+	Audited bool
+	// Model is the model that produced the verdict (empty for local verdicts)
+	Model string
+	// Provider is the backend that produced the verdict (ProviderGroq or ProviderLocal)
+	Provider string
+	// Source is the pipeline stage that decided the verdict. For cache hits Model and
+	// Provider are those of the original verdict
+	Source Stage
+
+	// Index is the position of the message in the batch (CheckMessagesContent only)
+	Index int
+	// Err is the error of this message's check, if any (CheckMessagesContent only)
+	Err error
+
+	// failOpen is true when the verdict was not confidently decided (e.g., the AI
+	// response could not be parsed) and the content was allowed by default.
+	// Such verdicts must never be cached
+	failOpen bool
+}
@@ -0,0 +1,45 @@
+package groq
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+const (
+	// defaultRetryBaseDelay is the first retry delay when RetryBaseDelay is not set
+	defaultRetryBaseDelay = 200 * time.Millisecond
+	// maxRetryDelay caps the backoff, including provider Retry-After hints
+	maxRetryDelay = 10 * time.Second
+)
+
+// isRetryableError reports whether the error is transient: rate limits and
+// provider-side failures (5xx, overloaded, over capacity)
+func isRetryableError(err error) bool {
+	err = MapGroqError(err)
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServer)
+}
+
+// retryDelay returns the exponential backoff with jitter for the attempt (0-based),
+// waiting at least the provider hint and at most maxRetryDelay
+func (c *Client) retryDelay(attempt int, hint time.Duration) time.Duration {
+	base := c.retryBaseDelay
+	if base <= 0 {
+		base = defaultRetryBaseDelay
+	}
+
+	delay := base << attempt
+	if delay <= 0 || delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	// Equal jitter: half fixed, half random, to spread retries of concurrent calls
+	delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+	if hint > delay {
+		delay = hint
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
@@ -0,0 +1,30 @@
+package groq
+
+import "strings"
+
+// Severity is how harmful a rejected message is, so callers can auto-reject
+// high-severity content and queue lower severities for human review
+type Severity string
+
+const (
+	// SeverityLow is mildly problematic content (e.g., probable spam)
+	SeverityLow Severity = "low"
+	// SeverityMedium is clearly problematic content that may warrant review
+	SeverityMedium Severity = "medium"
+	// SeverityHigh is severe content (e.g., threats, slurs, scams)
+	SeverityHigh Severity = "high"
+)
+
+// parseSeverityField returns the field as a Severity, accepting any case. Returns ""
+// if missing or not one of low, medium or high
+func parseSeverityField(value interface{}) Severity {
+	text, ok := value.(string)
+	if !ok {
+		return ""
+	}
+	switch severity := Severity(strings.ToLower(strings.TrimSpace(text))); severity {
+	case SeverityLow, SeverityMedium, SeverityHigh:
+		return severity
+	}
+	return ""
+}
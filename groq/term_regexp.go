@@ -0,0 +1,111 @@
+package groq
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// regexTermPrefix marks a blocked term as a regex (e.g., "re:f[\W_]*u[\W_]*c[\W_]*k")
+// for phone numbers, obfuscated URLs and spaced-out words that literal terms miss
+const regexTermPrefix = "re:"
+
+// termRegexps holds the compiled "re:" entries of a blocked terms list, keyed by the
+// term as written. It lives with the list it was compiled from, so reloading the list
+// replaces it. Invalid entries are stored as nil so they are only logged at load time
+type termRegexps map[string]*regexp.Regexp
+
+// isRegexTerm reports whether the blocked term is a regex entry
+func isRegexTerm(term string) bool {
+	return strings.HasPrefix(term, regexTermPrefix)
+}
+
+// compileTermRegexp compiles a "re:" blocked term. Regex terms are case-insensitive
+// like literal terms and limited to maxPatternLength characters. Regexes matching the
+// empty string are rejected, since they would block every message
+func compileTermRegexp(term string) (*regexp.Regexp, error) {
+	expr := strings.TrimSpace(strings.TrimPrefix(term, regexTermPrefix))
+	if expr == "" || len(expr) > maxPatternLength {
+		return nil, fmt.Errorf("empty or longer than %d characters", maxPatternLength)
+	}
+	re, err := regexp.Compile("(?i)" + expr)
+	if err != nil {
+		return nil, err
+	}
+	if re.MatchString("") {
+		return nil, fmt.Errorf("matches the empty string")
+	}
+	return re, nil
+}
+
+// compileTermRegexps compiles the regex entries of a blocked terms list at load time,
+// logging the invalid ones
+func compileTermRegexps(terms []string) termRegexps {
+	regexps := make(termRegexps)
+	for _, raw := range terms {
+		term := parseTermEntry(raw).term
+		if !isRegexTerm(term) {
+			continue
+		}
+		if _, ok := regexps[term]; ok {
+			continue
+		}
+		re, err := compileTermRegexp(term)
+		if err != nil {
+			log.Printf("Skipping invalid regex blocked term %q: %v", truncatePattern(term), err)
+		}
+		regexps[term] = re
+	}
+	return regexps
+}
+
+// with returns a new set with the entries of both sets, leaving r unchanged so
+// concurrent readers of r are not affected
+func (r termRegexps) with(other termRegexps) termRegexps {
+	merged := make(termRegexps, len(r)+len(other))
+	for term, re := range r {
+		merged[term] = re
+	}
+	for term, re := range other {
+		merged[term] = re
+	}
+	return merged
+}
+
+// lookup returns the compiled regex of a "re:" term, or nil if it is invalid. Terms
+// that were not compiled with the list (e.g., lists passed to the package-level
+// functions) are compiled on each call
+func (r termRegexps) lookup(term string) *regexp.Regexp {
+	if re, ok := r[term]; ok {
+		return re
+	}
+	re, _ := compileTermRegexp(term)
+	return re
+}
+
+// matchRegexTerm reports whether the message matches the "re:" blocked term
+func matchRegexTerm(messageText string, term string, regexps termRegexps) bool {
+	re := regexps.lookup(term)
+	return re != nil && re.MatchString(messageText)
+}
+
+// findRegexTermMatches returns the matches of the "re:" blocked term with rune offsets
+func findRegexTermMatches(message string, term string, regexps termRegexps) []Match {
+	re := regexps.lookup(term)
+	if re == nil {
+		return nil
+	}
+
+	var matches []Match
+	for _, loc := range re.FindAllStringIndex(message, -1) {
+		if loc[0] == loc[1] {
+			continue
+		}
+		start := utf8.RuneCountInString(message[:loc[0]])
+		end := start + utf8.RuneCountInString(message[loc[0]:loc[1]])
+		matches = append(matches, Match{Term: term, Start: start, End: end})
+	}
+	return matches
+}
@@ -0,0 +1,150 @@
+package groq
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	// defaultTermsReloadInterval is how often watched blocked terms are polled
+	defaultTermsReloadInterval = time.Minute
+	// maxTermsSourceSize bounds the size of a blocked terms file or response
+	maxTermsSourceSize = 10 << 20
+)
+
+// termsHTTPClient fetches blocked terms lists, bounding each request so a hung
+// endpoint cannot stall the reloads
+var termsHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// LoadBlockedTermsFromFile reads a blocked terms list from a file in the same format
+// as blocked_terms.txt (one term per line, # for comments, "re:" for regexes)
+func LoadBlockedTermsFromFile(path string) ([]string, error) {
+	content, err := readBlockedTermsFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlockedTerms(string(content)), nil
+}
+
+// LoadBlockedTermsFromURL fetches a blocked terms list over HTTP in the same format
+// as blocked_terms.txt
+func LoadBlockedTermsFromURL(ctx context.Context, url string) ([]string, error) {
+	content, err := fetchBlockedTerms(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlockedTerms(string(content)), nil
+}
+
+// WatchBlockedTermsFile loads the blocked terms from the file and polls it every
+// interval (defaults to 1m), swapping the client's list whenever the content changes,
+// so the moderation team can update it without a redeploy. It runs until ctx is done
+// Reload failures are logged and the current list is kept, so a bad edit (including
+// an empty list) never takes moderation down; until the first successful load the
+// configured or embedded list is used
+func (c *Client) WatchBlockedTermsFile(ctx context.Context, path string, interval time.Duration) {
+	c.watchBlockedTerms(ctx, path, interval, func(ctx context.Context) ([]byte, error) {
+		return readBlockedTermsFile(path)
+	})
+}
+
+// WatchBlockedTermsURL is like WatchBlockedTermsFile but polls an HTTP endpoint
+func (c *Client) WatchBlockedTermsURL(ctx context.Context, url string, interval time.Duration) {
+	c.watchBlockedTerms(ctx, url, interval, func(ctx context.Context) ([]byte, error) {
+		return fetchBlockedTerms(ctx, url)
+	})
+}
+
+// watchBlockedTerms polls the source in a goroutine and reloads the terms on change
+func (c *Client) watchBlockedTerms(ctx context.Context, source string, interval time.Duration, read func(ctx context.Context) ([]byte, error)) {
+	if c == nil {
+		log.Printf("Groq client not initialized, not watching blocked terms from %s", source)
+		return
+	}
+	if interval <= 0 {
+		interval = defaultTermsReloadInterval
+	}
+
+	var lastHash [sha256.Size]byte
+	reload := func() {
+		content, err := read(ctx)
+		if err != nil {
+			log.Printf("Error reloading blocked terms from %s, keeping current list: %v", source, err)
+			return
+		}
+
+		hash := sha256.Sum256(content)
+		if hash == lastHash {
+			return
+		}
+
+		terms := parseBlockedTerms(string(content))
+		if len(terms) == 0 {
+			log.Printf("Blocked terms from %s are empty, keeping current list", source)
+			return
+		}
+
+		lastHash = hash
+		c.setBlockedTerms(terms)
+		log.Printf("Reloaded %d blocked terms from %s", len(terms), source)
+	}
+
+	reload()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reload()
+			}
+		}
+	}()
+}
+
+// readBlockedTermsFile reads a blocked terms file, bounded to maxTermsSourceSize
+func readBlockedTermsFile(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening blocked terms file: %w", err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(io.LimitReader(file, maxTermsSourceSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading blocked terms file: %w", err)
+	}
+	return content, nil
+}
+
+// fetchBlockedTerms downloads a blocked terms list, bounded to maxTermsSourceSize
+func fetchBlockedTerms(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating blocked terms request: %w", err)
+	}
+
+	resp, err := termsHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching blocked terms: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error fetching blocked terms: unexpected status %d", resp.StatusCode)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, maxTermsSourceSize))
+	if err != nil {
+		return nil, fmt.Errorf("error reading blocked terms response: %w", err)
+	}
+	return content, nil
+}
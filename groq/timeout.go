@@ -0,0 +1,38 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+// withRequestTimeout bounds ctx with the configured RequestTimeout when it has no
+// deadline of its own, so a hung Groq call cannot block the caller indefinitely
+func (c *Client) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c == nil || c.requestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.requestTimeout)
+}
+
+// requestTimedOut reports whether err was caused by the RequestTimeout derived from
+// parent, as opposed to a deadline or cancellation of the caller
+func requestTimedOut(parent context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && parent.Err() == nil
+}
+
+// handleRequestTimeout applies the timeout policy: the message is allowed with the
+// local verdict (fail open, default) or the timeout is returned as an error
+// (fail closed, FailClosedOnTimeout)
+func (c *Client) handleRequestTimeout(err error) (ModerationResult, error) {
+	if c.failClosedOnTimeout {
+		log.Printf("Groq moderation timed out after %s, failing closed: %v", c.requestTimeout, err)
+		return ModerationResult{}, err
+	}
+	log.Printf("Groq moderation timed out after %s, allowing message: %v", c.requestTimeout, err)
+	c.observeStage(StageFallback)
+	return localFailOpenResult(), nil
+}
@@ -0,0 +1,104 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// translateMaxTokens leaves room for the translation besides the verdict
+const translateMaxTokens = 1024
+
+// ModerateAndTranslate moderates the message and translates it to targetLang
+// (e.g., "English") in a single model call, for moderators of cross-language chats
+// Blocked terms, patterns and domains are still checked locally and take precedence over the
+// model verdict. If the combined response cannot be parsed, it falls back to a
+// moderation-only check and the translation is empty
+func (c *Client) ModerateAndTranslate(ctx context.Context, messageText string, targetLang string) (ModerationResult, string, error) {
+	if strings.TrimSpace(messageText) == "" {
+		return c.emptyMessageResult(messageText), "", nil
+	}
+	if c == nil || c.client == nil {
+		result, err := c.CheckMessageContentDetailed(ctx, messageText)
+		return result, "", err
+	}
+
+	settings := c.defaultCheckSettings()
+	prompt := moderateAndTranslatePrompt(TruncateInput(replaceTrustedLinks(messageText, c.allowedDomains), c.maxInputChars), targetLang, c.responseFields)
+	responseText, err := c.completeModeration(ctx, c.withLocaleInstruction(ctx, prompt), c.GetModel(), translateMaxTokens)
+	if err != nil {
+		if shouldFallBackToLocal(err) {
+			log.Printf("Falling back to moderation-only check without translation: %v", err)
+			result, err := c.CheckMessageContentDetailed(ctx, messageText)
+			return result, "", err
+		}
+		return ModerationResult{}, "", err
+	}
+
+	result, translation, err := c.parseModerateAndTranslateResponse(responseText)
+	if err != nil {
+		log.Printf("Error parsing moderate and translate response, falling back to moderation-only: %v, response: %s", err, responseText)
+		result, err := c.CheckMessageContentDetailed(ctx, messageText)
+		return result, "", err
+	}
+	result.Model = c.GetModel()
+	result.Provider = ProviderGroq
+	result.Source = StageAI
+
+	// Local checks take precedence over the model verdict
+	if local, blocked := c.checkLocal(messageText, settings); blocked {
+		result = local
+	}
+
+	return c.finalizeResult(result, settings), translation, nil
+}
+
+// parseModerateAndTranslateResponse parses the verdict using the configured field
+// names and the "translation" field
+func (c *Client) parseModerateAndTranslateResponse(responseText string) (ModerationResult, string, error) {
+	var fields struct {
+		Translation *string `json:"translation"`
+	}
+	if err := unmarshalResponse(responseText, &fields); err != nil {
+		return ModerationResult{}, "", err
+	}
+	if fields.Translation == nil {
+		return ModerationResult{}, "", fmt.Errorf("missing translation field")
+	}
+
+	result, err := c.parseModerationResponse(responseText)
+	if err != nil {
+		return ModerationResult{}, "", err
+	}
+	if result.IsMalicious && result.ErrorCode == "" {
+		result.ErrorCode = c.defaultErrorCode
+	}
+	return result, *fields.Translation, nil
+}
+
+// moderateAndTranslatePrompt asks for the verdict, using the configured response field
+// names, and the translation in one JSON object
+func moderateAndTranslatePrompt(messageText string, targetLang string, fields ResponseFieldNames) string {
+	return fmt.Sprintf(`Analyze the following message and determine if it contains malicious, inappropriate, spam, or harmful content. Also translate the message to %[1]s.
+
+Message: "%[2]s"
+
+Respond with ONLY a JSON object in this exact format:
+{
+  "%[3]s": true or false,
+  "%[4]s": "ERROR_CODE" or null,
+  "%[5]s": "brief reason",
+  "translation": "the message translated to %[1]s"
+}
+
+Error codes to use if malicious:
+- CONTENT_SPAM: for spam messages
+- CONTENT_INAPPROPRIATE: for inappropriate language or content
+- CONTENT_HARASSMENT: for harassment or bullying
+- CONTENT_SCAM: for scam or phishing attempts
+- CONTENT_VIOLENCE: for violent or threatening content
+- CONTENT_OTHER: for other malicious content
+
+If the message is safe, set %[3]s to false and %[4]s to null. Translate the message faithfully, including offensive words, so moderators can judge it. If it is already in %[1]s, copy it unchanged.`, targetLang, messageText, fields.IsMalicious, fields.ErrorCode, fields.Reason)
+}
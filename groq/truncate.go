@@ -0,0 +1,27 @@
+package groq
+
+// truncationEllipsis separates the kept start and end of a truncated input
+const truncationEllipsis = "..."
+
+// TruncateInput shortens the text to at most maxChars characters (runes), keeping
+// the first and last characters with an ellipsis in between, since abuse usually
+// appears at the start or the end of a message
+// Returns the text unchanged if maxChars <= 0 or the text is already short enough
+// Note: truncation can reduce accuracy for abuse in the middle of the text
+func TruncateInput(text string, maxChars int) string {
+	runes := []rune(text)
+	if maxChars <= 0 || len(runes) <= maxChars {
+		return text
+	}
+
+	ellipsisLength := len([]rune(truncationEllipsis))
+	if maxChars <= ellipsisLength {
+		return string(runes[:maxChars])
+	}
+
+	available := maxChars - ellipsisLength
+	headLength := available - available/2
+	tailLength := available / 2
+
+	return string(runes[:headLength]) + truncationEllipsis + string(runes[len(runes)-tailLength:])
+}
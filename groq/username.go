@@ -0,0 +1,198 @@
+package groq
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// defaultUsernameMinLength is the minimum username length when not configured
+	defaultUsernameMinLength = 3
+	// defaultUsernameMaxLength is the maximum username length when not configured
+	defaultUsernameMaxLength = 30
+)
+
+// usernameCharsetPattern restricts usernames to letters and digits in any script,
+// dots, underscores and hyphens
+var usernameCharsetPattern = regexp.MustCompile(`^[\p{L}\p{N}._-]+$`)
+
+// defaultReservedUsernames returns the names that cannot be used because they
+// could impersonate staff or system accounts
+func defaultReservedUsernames() []string {
+	return []string{
+		"admin",
+		"administrator",
+		"support",
+		"moderator",
+		"staff",
+		"talentpitch",
+		"system",
+		"root",
+		"help",
+		"official",
+	}
+}
+
+// defaultUsernamePromptTemplate returns the default prompt template for username
+// moderation, with the JSON format using the configured response field names
+func defaultUsernamePromptTemplate(fields ResponseFieldNames) PromptTemplate {
+	return func(username string) string {
+		return fmt.Sprintf(defaultUsernamePromptFormat, username, fields.IsMalicious, fields.ErrorCode, fields.Reason)
+	}
+}
+
+// defaultUsernamePromptFormat is the default username prompt, formatted with the
+// username and the verdict, error code and reason field names
+const defaultUsernamePromptFormat = `Analyze the following username and determine if it is offensive, inappropriate, impersonates staff or an official account, or hides offensive words (including misspellings, leetspeak or words joined together).
+
+Username: "%[1]s"
+
+Respond with ONLY a JSON object in this exact format:
+{
+  "%[2]s": true or false,
+  "%[3]s": "ERROR_CODE" or null,
+  "%[4]s": "brief reason"
+}
+
+Error codes to use if malicious:
+- CONTENT_INAPPROPRIATE: for offensive or inappropriate usernames
+- CONTENT_HARASSMENT: for usernames targeting or insulting someone
+- CONTENT_IMPERSONATION: for usernames impersonating staff or official accounts
+- CONTENT_OTHER: for other malicious usernames
+
+If the username is acceptable, set %[2]s to false and %[3]s to null.`
+
+// CheckUsername applies the username moderation policy, which is stricter than the
+// message policy:
+//   - length and charset rules are enforced first
+//   - reserved names (e.g., "admin", "support") cannot be used as a whole name or as
+//     one of its dot, underscore or hyphen separated parts
+//   - blocked terms are matched as substrings, not whole words
+//   - the AI check uses a username-specific prompt
+//
+// Content verdicts (blocked terms and AI) go through the same NonBlockingCodes,
+// ErrorCodeMapper, ReasonVerbosity and AuditOnly handling as messages.
+//
+// As with CheckMessageContent, if the Groq client is not initialized the AI check
+// is skipped and the username is allowed if it passes the local rules.
+func (c *Client) CheckUsername(ctx context.Context, username string) (ModerationResult, error) {
+	minLength, maxLength := defaultUsernameMinLength, defaultUsernameMaxLength
+	reservedNames := defaultReservedUsernames()
+	if c != nil {
+		minLength, maxLength = c.usernameMinLength, c.usernameMaxLength
+		reservedNames = c.reservedUsernames
+	}
+	blockedTerms, regexps := c.getBlockedTermSet()
+
+	length := utf8.RuneCountInString(username)
+	if length < minLength || length > maxLength {
+		return ModerationResult{
+			IsMalicious: true,
+			ErrorCode:   "USERNAME_INVALID_LENGTH",
+			Reason:      fmt.Sprintf("Username must be between %d and %d characters", minLength, maxLength),
+			Provider:    ProviderLocal,
+		}, nil
+	}
+
+	if !usernameCharsetPattern.MatchString(username) {
+		return ModerationResult{
+			IsMalicious: true,
+			ErrorCode:   "USERNAME_INVALID_CHARACTERS",
+			Reason:      "Username can only contain letters, digits, dots, underscores and hyphens",
+			Provider:    ProviderLocal,
+		}, nil
+	}
+
+	if reserved, found := isReservedUsername(username, reservedNames); found {
+		log.Printf("Username uses reserved name: %s", reserved)
+		return ModerationResult{
+			IsMalicious: true,
+			ErrorCode:   "USERNAME_RESERVED",
+			Reason:      "Username is reserved",
+			Provider:    ProviderLocal,
+		}, nil
+	}
+
+	settings := c.defaultCheckSettings()
+	if hasBlockedTerm, foundTerm := containsBlockedSubstring(username, blockedTerms, regexps); hasBlockedTerm {
+		log.Printf("Username contains blocked term: %s", foundTerm)
+		result := ModerationResult{
+			IsMalicious: true,
+			ErrorCode:   "CONTENT_INAPPROPRIATE",
+			Reason:      "Username contains inappropriate language",
+			Provider:    ProviderLocal,
+			Source:      StageBlockedTerms,
+		}
+		return c.auditResult(ctx, username, c.finalizeResult(result, settings)), nil
+	}
+
+	if c == nil || c.client == nil {
+		log.Printf("Groq client not initialized, allowing username")
+		return localFailOpenResult(), nil
+	}
+
+	result, err := c.moderateWithAI(ctx, c.usernamePromptBuilder(username))
+	if err != nil && shouldFallBackToLocal(err) {
+		// The local rules already passed, so the username is allowed
+		log.Printf("Falling back to local username verdict: %v", err)
+		return localFailOpenResult(), nil
+	}
+	if err != nil {
+		return result, err
+	}
+	return c.auditResult(ctx, username, c.finalizeResult(result, settings)), nil
+}
+
+// isReservedUsername checks if the username, or one of its parts split on dots,
+// underscores and hyphens, is a reserved name. Case and digits are ignored, so
+// "Admin_01" and "the.support" match but "badminton" does not
+func isReservedUsername(username string, reservedNames []string) (string, bool) {
+	parts := strings.FieldsFunc(username, isUsernameSeparator)
+	candidates := make(map[string]bool, len(parts)+1)
+	candidates[normalizeUsername(username)] = true
+	for _, part := range parts {
+		candidates[normalizeUsername(part)] = true
+	}
+
+	for _, name := range reservedNames {
+		reserved := normalizeUsername(name)
+		if reserved != "" && candidates[reserved] {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// isUsernameSeparator reports whether r separates the parts of a username
+func isUsernameSeparator(r rune) bool {
+	return r == '.' || r == '_' || r == '-' || r == ' '
+}
+
+// normalizeUsername lowercases the username and strips separators and digits
+func normalizeUsername(username string) string {
+	var b strings.Builder
+	for _, r := range stripUsernameSeparators(username) {
+		if unicode.IsDigit(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// stripUsernameSeparators lowercases the username and strips separators, keeping digits
+func stripUsernameSeparators(username string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(username) {
+		if isUsernameSeparator(r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,51 @@
+package groq
+
+import "fmt"
+
+// ReasonVerbosity controls how detailed the reason of a moderation verdict is
+type ReasonVerbosity int
+
+const (
+	// ReasonVerbosityTerse asks for a brief reason, suitable for end users (default)
+	ReasonVerbosityTerse ReasonVerbosity = iota
+	// ReasonVerbosityNone does not ask for a reason and leaves Reason empty
+	ReasonVerbosityNone
+	// ReasonVerbosityDetailed asks for a detailed explanation, suitable for moderators
+	ReasonVerbosityDetailed
+)
+
+// String returns a readable name for the verbosity
+func (v ReasonVerbosity) String() string {
+	switch v {
+	case ReasonVerbosityTerse:
+		return "terse"
+	case ReasonVerbosityNone:
+		return "none"
+	case ReasonVerbosityDetailed:
+		return "detailed"
+	}
+	return "unknown"
+}
+
+// promptReasonField returns the reason line of the JSON format requested in the
+// default prompt, including the leading comma, for the given reason field name
+func (v ReasonVerbosity) promptReasonField(name string) string {
+	switch v {
+	case ReasonVerbosityNone:
+		return ""
+	case ReasonVerbosityDetailed:
+		return fmt.Sprintf(",\n  %q: \"detailed explanation of which part of the message is problematic and why\"", name)
+	}
+	return fmt.Sprintf(",\n  %q: \"brief reason\"", name)
+}
+
+// maxTokens returns the completion token limit needed for the reason
+func (v ReasonVerbosity) maxTokens() int {
+	switch v {
+	case ReasonVerbosityNone:
+		return 60
+	case ReasonVerbosityDetailed:
+		return 400
+	}
+	return 150
+}
@@ -0,0 +1,115 @@
+package groq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultVisionModel is the vision-capable model used by CheckImageContent
+// unless Config.VisionModel is set
+const DefaultVisionModel = "meta-llama/llama-4-scout-17b-16e-instruct"
+
+var (
+	// ErrImageURLNotAllowed means the image URL is not https or its host is not in AllowedImageHosts
+	ErrImageURLNotAllowed = errors.New("image URL not allowed")
+	// ErrVisionUnsupported means the vision model rejected the image input
+	ErrVisionUnsupported = errors.New("model does not support image input")
+)
+
+// CheckImageContent checks if the image at the URL is inappropriate by sending the
+// URL to the VisionModel through the same OpenAI-compatible client. The URL is
+// validated before sending: it must be https and, if AllowedImageHosts is set, its
+// host must be one of them (or a subdomain). Returns ErrImageURLNotAllowed for
+// rejected URLs and ErrVisionUnsupported if the model does not accept images
+func (c *Client) CheckImageContent(ctx context.Context, imageURL string) (isMalicious bool, errorCode string, reason string, err error) {
+	if c == nil || c.client == nil {
+		return false, "", "", fmt.Errorf("groq client not initialized")
+	}
+	if err := c.validateImageURL(imageURL); err != nil {
+		return false, "", "", err
+	}
+
+	message := openai.ChatCompletionMessage{
+		Role: openai.ChatMessageRoleUser,
+		MultiContent: []openai.ChatMessagePart{
+			{Type: openai.ChatMessagePartTypeText, Text: c.withLocaleInstruction(ctx, c.imagePrompt())},
+			{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: imageURL}},
+		},
+	}
+	responseText, err := c.completeModerationMessage(ctx, message, c.visionModel, c.reasonVerbosity.maxTokens())
+	if err != nil {
+		if isVisionUnsupportedError(err) {
+			return false, "", "", fmt.Errorf("%w: %s: %v", ErrVisionUnsupported, c.visionModel, err)
+		}
+		return false, "", "", err
+	}
+
+	result := c.moderationResultFromResponse(responseText, c.visionModel)
+	if result.IsMalicious {
+		log.Printf("Image flagged as malicious: error_code=%s", result.ErrorCode)
+	}
+	return result.IsMalicious, c.mapErrorCode(result.ErrorCode), result.Reason, nil
+}
+
+// validateImageURL checks the scheme and the host of the image URL
+func (c *Client) validateImageURL(imageURL string) error {
+	parsed, err := url.Parse(imageURL)
+	if err != nil || parsed.Scheme != "https" || parsed.Hostname() == "" {
+		return fmt.Errorf("%w: %q must be an https URL", ErrImageURLNotAllowed, imageURL)
+	}
+	if len(c.allowedImageHosts) == 0 {
+		return nil
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	for _, allowed := range c.allowedImageHosts {
+		if host == allowed || strings.HasSuffix(host, "."+allowed) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: host %q", ErrImageURLNotAllowed, host)
+}
+
+// isVisionUnsupportedError detects the bad request returned when a text-only model
+// receives image parts
+func isVisionUnsupportedError(err error) bool {
+	statusCode, _ := providerErrorDetails(err)
+	if statusCode != http.StatusBadRequest {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	return strings.Contains(message, "image") || strings.Contains(message, "vision") || strings.Contains(message, "must be a string")
+}
+
+// imagePrompt returns the default image prompt with the configured response field names
+func (c *Client) imagePrompt() string {
+	return fmt.Sprintf(defaultImagePromptFormat, c.responseFields.IsMalicious, c.responseFields.ErrorCode, c.responseFields.Reason)
+}
+
+// defaultImagePromptFormat asks the vision model for the same JSON verdict as text
+// moderation, formatted with the verdict, error code and reason field names
+const defaultImagePromptFormat = `Analyze the attached image and determine if it contains inappropriate, sexual, violent, hateful, spam, or otherwise harmful content.
+
+Respond with ONLY a JSON object in this exact format:
+{
+  "%[1]s": true or false,
+  "%[2]s": "ERROR_CODE" or null,
+  "%[3]s": "brief explanation"
+}
+
+Error codes to use if malicious:
+- CONTENT_SPAM: for spam or advertising images
+- CONTENT_INAPPROPRIATE: for sexual or otherwise inappropriate content
+- CONTENT_HARASSMENT: for harassment or bullying
+- CONTENT_SCAM: for scam or phishing attempts
+- CONTENT_VIOLENCE: for violent or threatening content
+- CONTENT_OTHER: for other harmful content
+
+If the image is safe, set %[1]s to false and %[2]s to null.`
@@ -0,0 +1,80 @@
+package helpers
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// customClaimsJSON has the fields of CustomClaims without its JSON methods
+type customClaimsJSON CustomClaims
+
+// MarshalJSON encodes the typed claims and the Extra claims at the same level
+// Extra claims cannot override the typed ones
+func (c CustomClaims) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(customClaimsJSON(c))
+	if err != nil || len(c.Extra) == 0 {
+		return data, err
+	}
+
+	claims := make(map[string]interface{})
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return nil, err
+	}
+	for key, value := range c.Extra {
+		if !isStandardClaim(key) {
+			claims[key] = value
+		}
+	}
+	return json.Marshal(claims)
+}
+
+// UnmarshalJSON decodes the typed claims and keeps the other claims in Extra
+func (c *CustomClaims) UnmarshalJSON(data []byte) error {
+	var typed customClaimsJSON
+	if err := json.Unmarshal(data, &typed); err != nil {
+		return err
+	}
+
+	var claims map[string]interface{}
+	if err := json.Unmarshal(data, &claims); err != nil {
+		return err
+	}
+	typed.Extra = nil
+	for key, value := range claims {
+		if isStandardClaim(key) {
+			continue
+		}
+		if typed.Extra == nil {
+			typed.Extra = make(map[string]interface{})
+		}
+		typed.Extra[key] = value
+	}
+
+	*c = CustomClaims(typed)
+	return nil
+}
+
+// GetClaim returns the claim with the given JSON name, either a typed field (e.g.,
+// "email") or an extra claim (e.g., "organization_id"). Extra claims keep their
+// decoded JSON type, so numbers are float64
+func (c CustomClaims) GetClaim(key string) (interface{}, bool) {
+	claimsValue := reflect.ValueOf(c)
+	claimsType := claimsValue.Type()
+	for i := 0; i < claimsType.NumField(); i++ {
+		if name := claimName(claimsType.Field(i)); name != "" && name == key {
+			return claimsValue.Field(i).Interface(), true
+		}
+	}
+	value, ok := c.Extra[key]
+	return value, ok
+}
+
+// claimName returns the JSON name of a CustomClaims field, "" for fields not encoded
+func claimName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -1,8 +1,12 @@
 package helpers
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"reflect"
 	"strconv"
+	"sync/atomic"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -10,26 +14,63 @@ import (
 
 // CustomClaims represents the JWT claims structure
 type CustomClaims struct {
-	Issuer         string `json:"iss"`
-	ID             string `json:"sub"` //this is an string to get an equivalent token with those PHP generated
-	IssuedAt       int64  `json:"iat"`
-	ExpirationTime int64  `json:"exp"`
-	Name           string `json:"name"`
-	Email          string `json:"email"`
-	Avatar         string `json:"avatar"`
-	About          string `json:"about"`
-	AboutVideo     string `json:"about_video"`
-	ProfileId      uint   `json:"profile_id"`
+	Issuer         string   `json:"iss"`
+	ID             string   `json:"sub"` //this is an string to get an equivalent token with those PHP generated
+	IssuedAt       int64    `json:"iat"`
+	ExpirationTime int64    `json:"exp"`
+	Name           string   `json:"name"`
+	Email          string   `json:"email"`
+	Avatar         string   `json:"avatar"`
+	About          string   `json:"about"`
+	AboutVideo     string   `json:"about_video"`
+	ProfileId      uint     `json:"profile_id"`
+	Roles          []string `json:"roles,omitempty"`
+	JTI            string   `json:"jti,omitempty"`  // unique token ID, used for revocation
+	Audience       string   `json:"aud,omitempty"`  // intended recipient service
+	TokenType      string   `json:"type,omitempty"` // TokenTypeAccess or TokenTypeRefresh (empty in older tokens)
+	NotBefore      int64    `json:"nbf,omitempty"`  // the token is rejected before this time (0 = no restriction)
+
+	// Extra holds custom claims (e.g., "organization_id") encoded next to the typed
+	// ones. It is filled on validation with every claim not listed above; read it
+	// with GetClaim
+	Extra map[string]interface{} `json:"-"`
+}
+
+// Token types of the "type" claim
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+)
+
+// clockSkew is the leeway applied by CustomClaims.Valid, in nanoseconds
+var clockSkew atomic.Int64
+
+// SetClockSkew sets the leeway allowed on the iat, nbf and exp checks of every token
+// validation, to tolerate clock drift between nodes (defaults to zero)
+func SetClockSkew(skew time.Duration) {
+	if skew < 0 {
+		skew = 0
+	}
+	clockSkew.Store(int64(skew))
 }
 
 func (c CustomClaims) Valid() error {
-	now := time.Now().Unix()
-	if c.ExpirationTime < now {
+	return c.ValidWithLeeway(time.Duration(clockSkew.Load()))
+}
+
+// ValidWithLeeway validates the claims allowing the given leeway on the expiration,
+// issued at and not before checks
+func (c CustomClaims) ValidWithLeeway(leeway time.Duration) error {
+	now := time.Now()
+	if now.Add(-leeway).Unix() > c.ExpirationTime {
 		return jwt.NewValidationError("token is expired", jwt.ValidationErrorExpired)
 	}
-	if c.IssuedAt > now {
+	if now.Add(leeway).Unix() < c.IssuedAt {
 		return jwt.NewValidationError("token used before issued", jwt.ValidationErrorIssuedAt)
 	}
+	if c.NotBefore != 0 && now.Add(leeway).Unix() < c.NotBefore {
+		return jwt.NewValidationError("token is not valid yet", jwt.ValidationErrorNotValidYet)
+	}
 	return nil
 }
 
@@ -41,6 +82,16 @@ func (c CustomClaims) WithValidAt(now int64) jwt.Claims {
 	return &c
 }
 
+// HasRole checks if the claims include the role (case-sensitive)
+func (c CustomClaims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
 func (c CustomClaims) GetID() uint {
 	id, _ := strconv.Atoi(c.ID)
 	return uint(id)
@@ -54,6 +105,25 @@ type UserContext struct {
 	About      string
 	AboutVideo string
 	ProfileId  uint
+	Roles      []string
+}
+
+// TokenOptions holds the token settings of CreateTokenWithOptions
+type TokenOptions struct {
+	// Issuer is the iss claim, usually the URL of the issuing service
+	Issuer string
+	// Audience is the aud claim, the service the token is intended for (optional)
+	Audience string
+	// TTLSeconds is the time to live in seconds
+	TTLSeconds int64
+	// Refresh adds RefreshTTL to the time to live
+	Refresh    bool
+	RefreshTTL int64
+	// Extra adds custom claims (e.g., "tenant"); they cannot override the typed ones
+	Extra map[string]interface{}
+	// NotBefore sets the nbf claim for delayed-activation tokens (e.g., scheduled
+	// access grants). When it is in the future the time to live counts from it
+	NotBefore time.Time
 }
 
 // CreateToken creates a JWT token with the given user context
@@ -61,14 +131,64 @@ type UserContext struct {
 // ttlSeconds is the time to live in seconds
 // refreshTTL is added if refresh is true
 func CreateToken(user UserContext, url string, ttlSeconds int64, secretKey []byte, refresh bool, refreshTTL int64) (string, error) {
+	return CreateTokenWithOptions(user, secretKey, TokenOptions{
+		Issuer:     url,
+		TTLSeconds: ttlSeconds,
+		Refresh:    refresh,
+		RefreshTTL: refreshTTL,
+	})
+}
+
+// CreateTokenWithOptions is like CreateToken with the settings in opts, including
+// the audience checked by the JWT middleware's ExpectedAudience
+func CreateTokenWithOptions(user UserContext, secretKey []byte, opts TokenOptions) (string, error) {
+	claims, err := newCustomClaims(user, opts)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenString, err := token.SignedString(secretKey)
+	if err != nil {
+		return "", err
+	}
+
+	return tokenString, nil
+}
+
+// CreateTokenWithExtra creates a JWT token like CreateToken and merges extra claims
+// into it (e.g., a one-time action scope). Extra claims cannot override the standard
+// CustomClaims fields; read them back with CustomClaims.GetClaim or GetExtraClaims
+func CreateTokenWithExtra(user UserContext, extra map[string]interface{}, url string, ttlSeconds int64, secretKey []byte, refresh bool, refreshTTL int64) (string, error) {
+	return CreateTokenWithOptions(user, secretKey, TokenOptions{
+		Issuer:     url,
+		TTLSeconds: ttlSeconds,
+		Refresh:    refresh,
+		RefreshTTL: refreshTTL,
+		Extra:      extra,
+	})
+}
+
+// newCustomClaims builds the claims of a new token for the user, with a fresh token ID
+func newCustomClaims(user UserContext, opts TokenOptions) (CustomClaims, error) {
 	iat := time.Now()
-	exp := iat.Add(time.Duration(ttlSeconds) * time.Second)
-	if refresh {
-		exp = exp.Add(time.Duration(refreshTTL) * time.Second)
+	validFrom := iat
+	if opts.NotBefore.After(iat) {
+		validFrom = opts.NotBefore
+	}
+	exp := validFrom.Add(time.Duration(opts.TTLSeconds) * time.Second)
+	if opts.Refresh {
+		exp = exp.Add(time.Duration(opts.RefreshTTL) * time.Second)
 	}
 
-	claims := CustomClaims{
-		Issuer:         url,
+	jti, err := NewTokenID()
+	if err != nil {
+		return CustomClaims{}, err
+	}
+
+	return CustomClaims{
+		Issuer:         opts.Issuer,
 		IssuedAt:       iat.Unix(),
 		ExpirationTime: exp.Unix(),
 		ID:             user.ID,
@@ -78,9 +198,114 @@ func CreateToken(user UserContext, url string, ttlSeconds int64, secretKey []byt
 		About:          user.About,
 		AboutVideo:     user.AboutVideo,
 		ProfileId:      user.ProfileId,
+		Roles:          user.Roles,
+		JTI:            jti,
+		Audience:       opts.Audience,
+		TokenType:      TokenTypeAccess,
+		NotBefore:      notBeforeClaim(opts.NotBefore),
+		Extra:          opts.Extra,
+	}, nil
+}
+
+// notBeforeClaim converts the NotBefore option to the nbf claim (0 when not set)
+func notBeforeClaim(notBefore time.Time) int64 {
+	if notBefore.IsZero() {
+		return 0
 	}
+	return notBefore.Unix()
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// GetExtraClaims validates the token and returns the claims that are not part of
+// CustomClaims (the ones added with CreateTokenWithExtra)
+func GetExtraClaims(tokenString string, secretKey []byte) (map[string]interface{}, error) {
+	claims := &CustomClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, hmacKeyFunc(secretKey))
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	extra := make(map[string]interface{}, len(claims.Extra))
+	for key, value := range claims.Extra {
+		extra[key] = value
+	}
+	return extra, nil
+}
+
+// isStandardClaim checks if the claim name is one of the CustomClaims JSON fields,
+// including omitempty ones (e.g., "roles"), so extra claims can never set them
+func isStandardClaim(name string) bool {
+	claimsType := reflect.TypeOf(CustomClaims{})
+	for i := 0; i < claimsType.NumField(); i++ {
+		if tag := claimName(claimsType.Field(i)); tag != "" && tag == name {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRefreshGracePeriod is how long after expiring a token can still be refreshed with RefreshToken
+var DefaultRefreshGracePeriod = 24 * time.Hour
+
+// RefreshToken issues a new token with the same claims as oldToken and fresh iat/exp,
+// for sliding sessions without re-reading the user from the database
+// The old token must be correctly signed and either valid or expired for less than
+// DefaultRefreshGracePeriod
+func RefreshToken(oldToken string, secretKey []byte, ttlSeconds int64) (string, error) {
+	return RefreshTokenWithGrace(oldToken, secretKey, ttlSeconds, DefaultRefreshGracePeriod)
+}
+
+// RevocationChecker reports whether a token was revoked, e.g., backed by Redis
+type RevocationChecker interface {
+	// IsRevoked checks the token ID (jti claim)
+	IsRevoked(jti string) (bool, error)
+}
+
+// RefreshTokenWithGrace is like RefreshToken but rejects tokens expired for more than grace
+func RefreshTokenWithGrace(oldToken string, secretKey []byte, ttlSeconds int64, grace time.Duration) (string, error) {
+	return RefreshTokenWithRevocation(oldToken, secretKey, ttlSeconds, grace, nil)
+}
+
+// RefreshTokenWithRevocation is like RefreshTokenWithGrace but consults the checker
+// before reissuing, so tokens revoked on logout or for disabled accounts cannot be
+// exchanged for a new, unrevoked one. It fails closed: a checker error rejects the
+// refresh. Tokens without a jti claim cannot be revoked and are not checked
+// A nil checker skips the check
+func RefreshTokenWithRevocation(oldToken string, secretKey []byte, ttlSeconds int64, grace time.Duration, checker RevocationChecker) (string, error) {
+	claims, status, _ := ParseTokenDetailed(oldToken, secretKey)
+	switch status {
+	case ParseStatusValid:
+	case ParseStatusExpired:
+		expiredAt := time.Unix(claims.ExpirationTime, 0)
+		if time.Since(expiredAt) > grace {
+			return "", fmt.Errorf("token expired more than %s ago", grace)
+		}
+	default:
+		return "", fmt.Errorf("invalid token: %s", status)
+	}
+	if claims.TokenType == TokenTypeRefresh {
+		return "", fmt.Errorf("refresh tokens cannot be renewed, use ValidateRefreshToken")
+	}
+	if checker != nil && claims.JTI != "" {
+		revoked, err := checker.IsRevoked(claims.JTI)
+		if err != nil {
+			return "", fmt.Errorf("could not verify token revocation: %w", err)
+		}
+		if revoked {
+			return "", fmt.Errorf("token has been revoked")
+		}
+	}
+	iat := time.Now()
+	refreshed := *claims
+	refreshed.IssuedAt = iat.Unix()
+	refreshed.ExpirationTime = iat.Add(time.Duration(ttlSeconds) * time.Second).Unix()
+	// Rotate the token ID so the old token can be revoked independently
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+	refreshed.JTI = jti
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshed)
 
 	tokenString, err := token.SignedString(secretKey)
 	if err != nil {
@@ -90,13 +315,58 @@ func CreateToken(user UserContext, url string, ttlSeconds int64, secretKey []byt
 	return tokenString, nil
 }
 
-func GetTokenExpiration(tokenString string, secretKey []byte) (int64, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return secretKey, nil
+// GenerateRefreshToken issues a refresh token for the user, distinct from the access
+// token: a "type":"refresh" claim and a minimal payload (iss, sub, iat, exp, jti), so it
+// cannot be used as an access token. refreshTTL is the time to live in seconds,
+// usually much longer than the access token's
+func GenerateRefreshToken(user UserContext, url string, refreshTTL int64, secretKey []byte) (string, error) {
+	iat := time.Now()
+	jti, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":  url,
+		"sub":  user.ID,
+		"iat":  iat.Unix(),
+		"exp":  iat.Add(time.Duration(refreshTTL) * time.Second).Unix(),
+		"jti":  jti,
+		"type": TokenTypeRefresh,
 	})
+	return token.SignedString(secretKey)
+}
+
+// CreateTokenPair issues an access token (see CreateToken) and a refresh token
+// (see GenerateRefreshToken) for the user
+func CreateTokenPair(user UserContext, url string, accessTTL int64, refreshTTL int64, secretKey []byte) (accessToken string, refreshToken string, err error) {
+	accessToken, err = CreateToken(user, url, accessTTL, secretKey, false, 0)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err = GenerateRefreshToken(user, url, refreshTTL, secretKey)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// ValidateRefreshToken validates a refresh token and returns its claims (only Issuer,
+// ID, IssuedAt, ExpirationTime and JTI are set). Access tokens are rejected, so they
+// cannot be used to obtain new tokens
+func ValidateRefreshToken(tokenString string, secretKey []byte) (*CustomClaims, error) {
+	claims, status, err := ParseTokenDetailed(tokenString, secretKey)
+	if status != ParseStatusValid {
+		return nil, fmt.Errorf("invalid refresh token: %s: %w", status, err)
+	}
+	if claims.TokenType != TokenTypeRefresh {
+		return nil, fmt.Errorf("not a refresh token")
+	}
+	return claims, nil
+}
+
+func GetTokenExpiration(tokenString string, secretKey []byte) (int64, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, hmacKeyFunc(secretKey))
 	if err != nil || !token.Valid {
 		return 0, fmt.Errorf("invalid token")
 	}
@@ -108,3 +378,132 @@ func GetTokenExpiration(tokenString string, secretKey []byte) (int64, error) {
 
 	return claims.ExpirationTime, nil
 }
+
+// ParseStatus describes why a token was accepted or rejected
+type ParseStatus int
+
+const (
+	// ParseStatusValid means the token is valid
+	ParseStatusValid ParseStatus = iota
+	// ParseStatusExpired means the token is correctly signed but expired (offer a refresh)
+	ParseStatusExpired
+	// ParseStatusBadSignature means the signature or signing method is invalid (force login)
+	ParseStatusBadSignature
+	// ParseStatusMalformed means the token could not be decoded
+	ParseStatusMalformed
+	// ParseStatusNotYetValid means the token is correctly signed but used before it was issued
+	ParseStatusNotYetValid
+)
+
+// String returns a readable name for the status
+func (s ParseStatus) String() string {
+	switch s {
+	case ParseStatusValid:
+		return "valid"
+	case ParseStatusExpired:
+		return "expired"
+	case ParseStatusBadSignature:
+		return "bad_signature"
+	case ParseStatusMalformed:
+		return "malformed"
+	case ParseStatusNotYetValid:
+		return "not_yet_valid"
+	}
+	return "unknown"
+}
+
+// ParseToken parses an access token and validates its signature (HMAC only, like the
+// middleware), expiry and issue time, returning the full claims. It does not need
+// Gin, e.g., for queue consumers or CLI tools. Refresh tokens are rejected like in
+// the middleware, so they cannot be used as access tokens (use ValidateRefreshToken)
+// Use ParseTokenDetailed to tell an expired token from a tampered one; it does not
+// check the token type
+func ParseToken(tokenString string, secretKey []byte) (*CustomClaims, error) {
+	claims, status, err := ParseTokenDetailed(tokenString, secretKey)
+	if status != ParseStatusValid {
+		return nil, fmt.Errorf("invalid token: %s: %w", status, err)
+	}
+	if claims.TokenType == TokenTypeRefresh {
+		return nil, fmt.Errorf("invalid token: refresh tokens cannot be used as access tokens")
+	}
+	return claims, nil
+}
+
+// ParseTokenDetailed parses and validates a token, distinguishing an expired but
+// otherwise valid token (offer refresh) from a tampered or malformed one (force login)
+// For expired and not-yet-valid tokens the claims are returned along with the error,
+// since the signature was verified. For any other failure the claims are nil.
+func ParseTokenDetailed(tokenString string, secretKey []byte) (*CustomClaims, ParseStatus, error) {
+	return parseTokenDetailed(tokenString, hmacKeyFunc(secretKey))
+}
+
+// parseTokenDetailed implements ParseTokenDetailed with the given key function
+func parseTokenDetailed(tokenString string, keyFunc jwt.Keyfunc) (*CustomClaims, ParseStatus, error) {
+	claims := &CustomClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, keyFunc)
+	if err == nil && token.Valid {
+		return claims, ParseStatusValid, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("invalid token")
+	}
+
+	status := parseStatusFromError(err)
+	if status == ParseStatusExpired || status == ParseStatusNotYetValid {
+		return claims, status, err
+	}
+	return nil, status, err
+}
+
+// parseStatusFromError inspects the jwt.ValidationError bitmask
+// Signature problems take precedence over time-based ones, so a tampered expired
+// token is reported as a bad signature
+func parseStatusFromError(err error) ParseStatus {
+	vErr, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return ParseStatusMalformed
+	}
+
+	switch {
+	case vErr.Errors&jwt.ValidationErrorMalformed != 0:
+		return ParseStatusMalformed
+	case vErr.Errors&(jwt.ValidationErrorSignatureInvalid|jwt.ValidationErrorUnverifiable) != 0:
+		return ParseStatusBadSignature
+	case vErr.Errors&jwt.ValidationErrorExpired != 0:
+		return ParseStatusExpired
+	case vErr.Errors&(jwt.ValidationErrorIssuedAt|jwt.ValidationErrorNotValidYet) != 0:
+		return ParseStatusNotYetValid
+	}
+	return ParseStatusMalformed
+}
+
+// DecodeTokenUnverified decodes the token claims WITHOUT verifying the signature
+// nor the expiration. UNSAFE: the claims can be forged by anyone, never use them for
+// authentication or authorization decisions. It is only meant for inspecting tokens
+// (e.g., from logs in a support CLI) when the secret is not available
+func DecodeTokenUnverified(tokenString string) (*CustomClaims, error) {
+	claims := &CustomClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(tokenString, claims); err != nil {
+		return nil, fmt.Errorf("could not decode token: %w", err)
+	}
+	return claims, nil
+}
+
+// NewTokenID generates a random unique token ID for the jti claim
+func NewTokenID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("could not generate token ID: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// hmacKeyFunc returns a jwt.Keyfunc that only accepts HMAC signed tokens
+func hmacKeyFunc(secretKey []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return secretKey, nil
+	}
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"fmt"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// KeyProvider supplies the HMAC keys used to sign and verify tokens, identified by
+// the "kid" header, so the secret can be rotated without logging out every user:
+// during a rotation Key returns both the old and the new key
+type KeyProvider interface {
+	// CurrentKey returns the key ID and the key new tokens are signed with
+	CurrentKey() (kid string, key []byte)
+	// Key returns the verification key for the kid header ("" for tokens without kid)
+	Key(kid string) ([]byte, bool)
+}
+
+// KeySet is a static KeyProvider. Tokens without a kid header (issued before key IDs)
+// are verified with Keys[""] if set, e.g., the secret being rotated out
+type KeySet struct {
+	// CurrentKID is the ID of the key new tokens are signed with
+	CurrentKID string
+	// Keys maps the key IDs to the keys still accepted for verification
+	Keys map[string][]byte
+}
+
+// CurrentKey returns the current key ID and its key
+func (s KeySet) CurrentKey() (string, []byte) {
+	return s.CurrentKID, s.Keys[s.CurrentKID]
+}
+
+// Key returns the key for the key ID
+func (s KeySet) Key(kid string) ([]byte, bool) {
+	key, ok := s.Keys[kid]
+	return key, ok
+}
+
+// CreateTokenWithKeys is like CreateTokenWithOptions but signs with the current key of
+// the provider and sets its ID as the "kid" header
+func CreateTokenWithKeys(user UserContext, keys KeyProvider, opts TokenOptions) (string, error) {
+	claims, err := newCustomClaims(user, opts)
+	if err != nil {
+		return "", err
+	}
+
+	kid, key := keys.CurrentKey()
+	if len(key) == 0 {
+		return "", fmt.Errorf("no current signing key")
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	return token.SignedString(key)
+}
+
+// ParseTokenWithKeys is like ParseTokenDetailed but verifies the signature with the
+// provider key matching the "kid" header. Unknown key IDs are reported as a bad signature
+func ParseTokenWithKeys(tokenString string, keys KeyProvider) (*CustomClaims, ParseStatus, error) {
+	return parseTokenDetailed(tokenString, keyProviderKeyFunc(keys))
+}
+
+// keyProviderKeyFunc resolves the HMAC key from the "kid" header
+func keyProviderKeyFunc(keys KeyProvider) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.Key(kid)
+		if !ok || len(key) == 0 {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		return key, nil
+	}
+}
@@ -0,0 +1,301 @@
+package talentpitchtools
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/TalentPitchCode/talentpitch-tools-go/helpers"
+	"github.com/gin-gonic/gin"
+)
+
+// JWTConfig holds configuration for JWTMiddlewareWithConfig
+type JWTConfig struct {
+	// Secret is the HMAC secret used to validate tokens
+	Secret string
+	// HeaderName is the header the token is read from (defaults to "Authorization")
+	HeaderName string
+	// AuthScheme is the scheme expected before the token in the header, as in
+	// "Bearer <token>". When empty, the Authorization header accepts any scheme
+	// ("<scheme> <token>", as JWTMiddleware always did); for other headers (e.g.,
+	// "X-Auth-Token") an empty scheme reads the raw header value. Set it to "Bearer"
+	// to reject other schemes
+	AuthScheme string
+	// CookieName is a cookie the token is read from (e.g., "tp_session") when the
+	// header is missing
+	CookieName string
+	// TokenLookup declares the token sources tried in order, overriding HeaderName,
+	// AuthScheme and CookieName. It is a comma-separated list of "source:name" or
+	// "header:name:scheme" entries, where source is header, cookie or query (a "*"
+	// scheme accepts any scheme)
+	// e.g., "header:Authorization:Bearer,cookie:tp_session,header:X-Auth-Token"
+	TokenLookup string
+	// ErrorRenderer writes the error response of a rejected request, for teams with
+	// their own error envelope (defaults to the package ErrorResponder)
+	ErrorRenderer func(c *gin.Context, status int, response ErrorResponse)
+	// RevocationChecker is consulted after the signature is validated to reject
+	// revoked tokens (logout, disabled accounts). Tokens without a jti claim cannot
+	// be revoked and are not checked
+	RevocationChecker RevocationChecker
+	// ExpectedIssuers pins the accepted iss claims: when non-empty, tokens from other
+	// issuers sharing the secret are rejected with 403 token_invalid_issuer
+	ExpectedIssuers []string
+	// ExpectedAudience, when set, rejects tokens whose aud claim is different with
+	// 403 token_invalid_audience (see helpers.CreateTokenWithOptions)
+	ExpectedAudience string
+	// KeyProvider verifies tokens with the key matching their "kid" header instead of
+	// Secret, so the secret can be rotated while older tokens keep validating
+	// (see helpers.KeySet and helpers.CreateTokenWithKeys)
+	KeyProvider helpers.KeyProvider
+	// UserStatusChecker is consulted last, once the token is fully validated, to reject
+	// users suspended or banned while holding a valid token (403 user_inactive, 503 if
+	// the check fails). If not provided, no lookup is made
+	UserStatusChecker UserStatusChecker
+}
+
+// UserStatusChecker reports whether the user of a valid token is still allowed in,
+// e.g., backed by the users table or a cache of suspended accounts
+type UserStatusChecker interface {
+	IsActive(claims *helpers.CustomClaims) (bool, error)
+}
+
+// RevocationChecker reports whether a token was revoked, e.g., backed by Redis
+// It is the same interface used by helpers.RefreshTokenWithRevocation
+type RevocationChecker = helpers.RevocationChecker
+
+// JWT error codes returned by the JWT middlewares
+const (
+	// JWTErrorTokenMissing means no token was sent
+	JWTErrorTokenMissing = "token_missing"
+	// JWTErrorTokenMalformed means the token or its header could not be decoded
+	JWTErrorTokenMalformed = "token_malformed"
+	// JWTErrorTokenExpired means the token is correctly signed but expired (refresh it)
+	JWTErrorTokenExpired = "token_expired"
+	// JWTErrorTokenNotYetValid means the token is correctly signed but used before it was issued
+	JWTErrorTokenNotYetValid = "token_not_yet_valid"
+	// JWTErrorTokenBadSignature means the signature or signing method is invalid
+	JWTErrorTokenBadSignature = "token_bad_signature"
+	// JWTErrorTokenRevoked means the token was revoked (see RevocationChecker)
+	JWTErrorTokenRevoked = "token_revoked"
+	// JWTErrorRevocationCheckFailed means the revocation store could not be checked
+	JWTErrorRevocationCheckFailed = "revocation_check_failed"
+	// JWTErrorInvalidIssuer means the iss claim is not one of ExpectedIssuers
+	JWTErrorInvalidIssuer = "token_invalid_issuer"
+	// JWTErrorInvalidAudience means the aud claim is not ExpectedAudience
+	JWTErrorInvalidAudience = "token_invalid_audience"
+	// JWTErrorWrongTokenType means a refresh token was sent as an access token
+	JWTErrorWrongTokenType = "token_wrong_type"
+	// JWTErrorUserInactive means the UserStatusChecker reported the user as not active
+	JWTErrorUserInactive = "user_inactive"
+	// JWTErrorUserStatusCheckFailed means the UserStatusChecker could not be consulted
+	JWTErrorUserStatusCheckFailed = "user_status_check_failed"
+)
+
+// jwtErrorFromStatus maps a token parse status to the error code and message
+func jwtErrorFromStatus(status helpers.ParseStatus) (string, string) {
+	switch status {
+	case helpers.ParseStatusExpired:
+		return JWTErrorTokenExpired, "Token has expired"
+	case helpers.ParseStatusNotYetValid:
+		return JWTErrorTokenNotYetValid, "Token is not valid yet"
+	case helpers.ParseStatusBadSignature:
+		return JWTErrorTokenBadSignature, "Token signature is invalid"
+	}
+	return JWTErrorTokenMalformed, "Token is malformed"
+}
+
+// bearerChallenge builds the WWW-Authenticate header of RFC 6750: a bare challenge
+// when no token was sent and invalid_token with a description otherwise
+func bearerChallenge(code string, message string) string {
+	switch code {
+	case JWTErrorTokenMissing:
+		return "Bearer"
+	case JWTErrorTokenExpired:
+		message = "The token expired"
+	}
+	description := strings.ReplaceAll(strings.ReplaceAll(message, `\`, ""), `"`, "'")
+	return fmt.Sprintf(`Bearer error="invalid_token", error_description="%s"`, description)
+}
+
+// parseToken validates the token with the KeyProvider, or the Secret if not set
+func (cfg JWTConfig) parseToken(tokenString string) (*helpers.CustomClaims, helpers.ParseStatus, error) {
+	if cfg.KeyProvider != nil {
+		return helpers.ParseTokenWithKeys(tokenString, cfg.KeyProvider)
+	}
+	return helpers.ParseTokenDetailed(tokenString, []byte(cfg.Secret))
+}
+
+// jwtRejection is why validateToken rejected a token: the response of the required
+// middleware and the auth_error recorded by the optional one
+type jwtRejection struct {
+	status    int
+	code      string
+	message   string
+	authError string
+}
+
+// validateToken checks the token like JWTMiddlewareWithConfig: signature and time
+// claims, token type, issuer and audience, revocation and user status
+func (cfg JWTConfig) validateToken(c *gin.Context, tokenString string) (*helpers.CustomClaims, *jwtRejection) {
+	claims, status, err := cfg.parseToken(tokenString)
+	if err != nil {
+		code, message := jwtErrorFromStatus(status)
+		return nil, &jwtRejection{http.StatusUnauthorized, code, message, status.String()}
+	}
+
+	if claims.TokenType == helpers.TokenTypeRefresh {
+		return nil, &jwtRejection{http.StatusUnauthorized, JWTErrorWrongTokenType, "Refresh tokens cannot be used as access tokens", AuthErrorWrongTokenType}
+	}
+
+	if code, message := cfg.checkIssuerAndAudience(claims); code != "" {
+		return nil, &jwtRejection{http.StatusForbidden, code, message, strings.TrimPrefix(code, "token_")}
+	}
+
+	// Revocation is checked after the signature, so forged IDs never reach the store
+	if cfg.RevocationChecker != nil && claims.JTI != "" {
+		revoked, err := cfg.RevocationChecker.IsRevoked(claims.JTI)
+		if err != nil {
+			log.Printf("Error checking token revocation%s: %v", logRequestID(c), err)
+			return nil, &jwtRejection{http.StatusServiceUnavailable, JWTErrorRevocationCheckFailed, "Could not verify the token", JWTErrorRevocationCheckFailed}
+		}
+		if revoked {
+			return nil, &jwtRejection{http.StatusUnauthorized, JWTErrorTokenRevoked, "Token has been revoked", "revoked"}
+		}
+	}
+
+	if cfg.UserStatusChecker != nil {
+		active, err := cfg.UserStatusChecker.IsActive(claims)
+		if err != nil {
+			log.Printf("Error checking user status%s: %v", logRequestID(c), err)
+			return nil, &jwtRejection{http.StatusServiceUnavailable, JWTErrorUserStatusCheckFailed, "Could not verify the user", JWTErrorUserStatusCheckFailed}
+		}
+		if !active {
+			return nil, &jwtRejection{http.StatusForbidden, JWTErrorUserInactive, "User account is not active", JWTErrorUserInactive}
+		}
+	}
+	return claims, nil
+}
+
+// checkIssuerAndAudience validates the iss and aud claims against the expected values
+// Returns the error code and message, or "" if the claims are accepted
+func (cfg JWTConfig) checkIssuerAndAudience(claims *helpers.CustomClaims) (string, string) {
+	if len(cfg.ExpectedIssuers) > 0 {
+		accepted := false
+		for _, issuer := range cfg.ExpectedIssuers {
+			if claims.Issuer == issuer {
+				accepted = true
+				break
+			}
+		}
+		if !accepted {
+			return JWTErrorInvalidIssuer, "Token issuer is not accepted"
+		}
+	}
+	if cfg.ExpectedAudience != "" && claims.Audience != cfg.ExpectedAudience {
+		return JWTErrorInvalidAudience, "Token is not intended for this service"
+	}
+	return "", ""
+}
+
+// abort rejects the request with the configured ErrorRenderer
+// 401 responses carry the Bearer challenge, so OAuth clients know to reauthenticate
+func (cfg JWTConfig) abort(c *gin.Context, status int, code string, message string) {
+	if status == http.StatusUnauthorized {
+		c.Header("WWW-Authenticate", bearerChallenge(code, message))
+	}
+	if cfg.ErrorRenderer == nil {
+		abortWithError(c, status, code, message)
+		return
+	}
+	cfg.ErrorRenderer(c, status, newErrorResponse(c, code, message))
+	c.Abort()
+}
+
+// tokenSource is a place the token can be read from
+type tokenSource struct {
+	source string
+	name   string
+	scheme string
+}
+
+// anyAuthScheme accepts "<scheme> <token>" whatever the scheme is
+const anyAuthScheme = "*"
+
+// errTokenMissing means none of the sources had a token
+var errTokenMissing = fmt.Errorf("token is required")
+
+// tokenSources returns the token sources of the configuration in lookup order
+func (cfg JWTConfig) tokenSources() ([]tokenSource, error) {
+	if cfg.TokenLookup == "" {
+		headerName := cfg.HeaderName
+		scheme := cfg.AuthScheme
+		if headerName == "" {
+			headerName = "Authorization"
+		}
+		if scheme == "" && strings.EqualFold(headerName, "Authorization") {
+			scheme = anyAuthScheme
+		}
+
+		sources := []tokenSource{{source: "header", name: headerName, scheme: scheme}}
+		if cfg.CookieName != "" {
+			sources = append(sources, tokenSource{source: "cookie", name: cfg.CookieName})
+		}
+		return sources, nil
+	}
+
+	var sources []tokenSource
+	for _, entry := range strings.Split(cfg.TokenLookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) < 2 || parts[1] == "" {
+			return nil, fmt.Errorf("invalid token lookup entry %q", entry)
+		}
+		source := tokenSource{source: strings.ToLower(parts[0]), name: parts[1]}
+		if len(parts) == 3 {
+			source.scheme = strings.TrimSpace(parts[2])
+		}
+		switch source.source {
+		case "header", "cookie", "query":
+		default:
+			return nil, fmt.Errorf("invalid token lookup source %q", parts[0])
+		}
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// extractToken returns the token from the first source that has a value
+// Returns errTokenMissing if no source has one, or an error if the value of the
+// first source found does not have the expected scheme
+func extractToken(c *gin.Context, sources []tokenSource) (string, error) {
+	for _, source := range sources {
+		var value string
+		switch source.source {
+		case "header":
+			value = c.GetHeader(source.name)
+		case "cookie":
+			value, _ = c.Cookie(source.name)
+		case "query":
+			value = c.Query(source.name)
+		}
+		if value == "" {
+			continue
+		}
+
+		if source.scheme == "" {
+			return strings.TrimSpace(value), nil
+		}
+		parts := strings.Split(value, " ")
+		if source.scheme == anyAuthScheme {
+			if len(parts) != 2 || parts[1] == "" {
+				return "", fmt.Errorf("%s must be in the format: <scheme> <token>", source.name)
+			}
+			return parts[1], nil
+		}
+		if len(parts) != 2 || !strings.EqualFold(parts[0], source.scheme) || parts[1] == "" {
+			return "", fmt.Errorf("%s must be in the format: %s <token>", source.name, source.scheme)
+		}
+		return parts[1], nil
+	}
+	return "", errTokenMissing
+}
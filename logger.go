@@ -0,0 +1,84 @@
+package talentpitchtools
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogConfig holds configuration for LoggerMiddleware
+type LogConfig struct {
+	// Logger receives the access log records (defaults to a JSON logger on stdout)
+	Logger *slog.Logger
+	// SkipPaths are paths not logged, like noisy health checks ("/health", "/metrics")
+	// Patterns match like in JWTMiddlewareWithSkip
+	SkipPaths []string
+}
+
+/*****************************************************************
+* Function Name: LoggerMiddleware
+* Description: Middleware that logs each request as a structured
+* record with method, path, status, latency, client IP, request ID
+* and the authenticated user's ID and profile ID, through the
+* configured *slog.Logger so it integrates with the service logging
+* setup. It is the package's access log (AccessLogMiddleware wraps it)
+* 5xx responses are logged as errors and 4xx as warnings
+* Values are read after the handlers run, so the middleware can be
+* registered before the ones that store them
+*****************************************************************/
+func LoggerMiddleware(cfg LogConfig) gin.HandlerFunc {
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+	}
+
+	return func(c *gin.Context) {
+		if matchesAnyPath(c.Request.URL.Path, cfg.SkipPaths) {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		clientIP := c.GetString("client_ip")
+		if clientIP == "" {
+			clientIP = getClientIP(c)
+		}
+
+		status := c.Writer.Status()
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("client_ip", clientIP),
+		}
+		if requestID := GetRequestID(c); requestID != "" {
+			attrs = append(attrs, slog.String("request_id", requestID))
+		}
+		if user, ok := GetUser(c); ok {
+			if user.ID != "" {
+				attrs = append(attrs, slog.String("user_id", user.ID))
+			}
+			if user.ProfileId != 0 {
+				attrs = append(attrs, slog.Uint64("profile_id", uint64(user.ProfileId)))
+			}
+		}
+		if authError := GetAuthError(c); authError != "" {
+			attrs = append(attrs, slog.String("auth_error", authError))
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logger.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
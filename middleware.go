@@ -2,19 +2,39 @@ package talentpitchtools
 
 import (
 	"fmt"
+	"mime"
 	"net"
 	"net/http"
+	"path"
 	"strings"
 
 	"github.com/TalentPitchCode/talentpitch-tools-go/helpers"
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-contrib/location"
 	"github.com/gin-gonic/gin"
 )
 
 // SetupLocationWithTrustedProxies configures Gin router with location middleware
 // and trusted proxies settings. This function should be called before setting up routes.
-func SetupLocationWithTrustedProxies(r *gin.Engine, jwtSecret string, trustedProxies []string) (*gin.Engine, error) {
+// Optional middlewares (e.g., WithCORS) are enabled with opts
+// It is SetupTalentpitchMiddlewaresWithOptions with DefaultMiddlewareOptions
+func SetupLocationWithTrustedProxies(r *gin.Engine, jwtSecret string, trustedProxies []string, opts ...SetupOption) (*gin.Engine, error) {
+	options := DefaultMiddlewareOptions()
+	options.JWTSecret = jwtSecret
+	options.TrustedProxies = trustedProxies
+	newSetupOptions(opts).apply(&options)
+	return SetupTalentpitchMiddlewaresWithOptions(r, options)
+}
+
+// SetupTalentpitchMiddlewaresWithOptions registers the middlewares enabled in opts, in
+// the order of opts.Order (DefaultMiddlewareOrder if empty). This function should be
+// called before setting up routes. Returns an error if Order names an unknown
+// middleware or leaves out an enabled one
+func SetupTalentpitchMiddlewaresWithOptions(r *gin.Engine, opts MiddlewareOptions) (*gin.Engine, error) {
+	middlewares, err := opts.middlewares()
+	if err != nil {
+		return r, err
+	}
+
 	// Trust all proxies (Required for Cloudflare -> AWS ALB -> EKS)
 	// Security is handled by AWS Security Groups and VPC isolation
 	// Ingress: Tu ALB (k8s-developm-nginx...) tiene los Security Groups sg-087e406bb9c504ccf y sg-00191405ecc229d51.
@@ -23,20 +43,9 @@ func SetupLocationWithTrustedProxies(r *gin.Engine, jwtSecret string, trustedPro
 	// ✅ Existe una regla que permite tráfico desde el ALB hacia tus nodos en el rango de puertos 3030-5678 (que incluye tu puerto 5001).
 	// ✅ NO existen reglas de entrada abiertas (0.0.0.0/0) en tus nodos.
 	// Conclusión: Nadie puede conectarse directamente a tus Pods desde internet saltándose el Load Balancer. Por lo tanto, confiar en todas las IPs (0.0.0.0/0) a nivel de aplicación es seguro porque la red ya filtra quién puede hablarte (solo el ALB).
-	r.SetTrustedProxies(trustedProxies)
-
-	// Use location middleware (handles scheme/host from headers)
-	// Note: c.ClientIP() should work automatically after SetTrustedProxies
-	r.Use(location.Default())
-
-	// Use ClientIP middleware to calculate and store client IP in context
-	r.Use(clientIPMiddleware())
-
-	// Use JWT middleware if jwtSecret is provided
-	if jwtSecret != "" {
-		r.Use(optionalJWTMiddleware(jwtSecret))
-	}
+	r.SetTrustedProxies(opts.TrustedProxies)
 
+	r.Use(middlewares...)
 	return r, nil
 }
 
@@ -45,40 +54,46 @@ func SetupLocationWithTrustedProxies(r *gin.Engine, jwtSecret string, trustedPro
 * Description: Optional middleware for JWT validation
 * If token is present and valid, sets user in context
 * If token is missing or invalid, continues without setting user
+* Tokens are validated like JWTMiddlewareWithConfig (key provider,
+* issuer/audience, revocation, user status); rejected ones are treated
+* as anonymous and annotated in the context with "auth_error"
+* (expired, bad_signature, revoked...), see GetAuthError
+* CORS preflight requests (OPTIONS with Origin and
+* Access-Control-Request-Method) are let through untouched
+* The token sources must be valid (see MiddlewareOptions.middlewares)
 *****************************************************************/
-func optionalJWTMiddleware(jwtSecret string) gin.HandlerFunc {
+func optionalJWTMiddleware(cfg JWTConfig) gin.HandlerFunc {
+	sources, err := cfg.tokenSources()
+	if err != nil {
+		panic(fmt.Sprintf("talentpitchtools: %v", err))
+	}
+
 	return func(c *gin.Context) {
-		tokenHeader := c.GetHeader("Authorization")
-		if tokenHeader == "" {
-			// No token provided, continue without authentication
+		if isPreflightRequest(c) {
 			c.Next()
 			return
 		}
 
-		tokenSplit := strings.Split(tokenHeader, " ")
-		if len(tokenSplit) != 2 {
+		tokenString, err := extractToken(c, sources)
+		if err == errTokenMissing {
+			// No token provided, continue without authentication
+			c.Next()
+			return
+		}
+		if err != nil {
 			// Invalid token format, continue without authentication
+			c.Set(AuthErrorKey, helpers.ParseStatusMalformed.String())
 			c.Next()
 			return
 		}
 
-		tokenString := tokenSplit[1]
-		//token validation
-		token, err := jwt.ParseWithClaims(tokenString, &helpers.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
+		claims, rejection := cfg.validateToken(c, tokenString)
+		if rejection != nil {
 			// Invalid token, continue without authentication
+			c.Set(AuthErrorKey, rejection.authError)
 			c.Next()
 			return
 		}
-
-		// If token is valid, set user in context
-		claims := token.Claims.(*helpers.CustomClaims)
 		c.Set("user", claims)
 
 		c.Next()
@@ -88,45 +103,108 @@ func optionalJWTMiddleware(jwtSecret string) gin.HandlerFunc {
 /*****************************************************************
 * Function Name: JWTMiddleware
 * Description: Middleware for validate JWT (required authentication)
+* Reads the token from the "Authorization: <scheme> <token>" header
+* (any scheme, usually Bearer; see JWTConfig.AuthScheme to enforce one)
+* CORS preflight requests (OPTIONS with Origin and
+* Access-Control-Request-Method) are let through without a token,
+* since browsers never send Authorization on them
 *****************************************************************/
 func JWTMiddleware(jwtSecret string) gin.HandlerFunc {
+	return JWTMiddlewareWithConfig(JWTConfig{Secret: jwtSecret})
+}
+
+/*****************************************************************
+* Function Name: JWTMiddlewareWithConfig
+* Description: JWTMiddleware reading the token from the configured
+* sources (headers with or without scheme, cookies, query params),
+* tried in order. See JWTConfig
+* An invalid TokenLookup panics at setup, like an invalid route
+* Errors: 401 token_missing/token_malformed when the token cannot be
+* read, 401 token_expired, token_not_yet_valid, token_bad_signature
+* or token_malformed when it is rejected, 403 token_invalid_issuer or
+* token_invalid_audience when ExpectedIssuers or ExpectedAudience do
+* not match, 401 token_wrong_type for refresh tokens, 401 token_revoked when the
+* RevocationChecker reports it (503 if the check fails, fail closed),
+* 403 user_inactive when the UserStatusChecker rejects the user (503
+* user_status_check_failed if it fails)
+* Every 401 sets WWW-Authenticate: a bare Bearer challenge when the
+* token is missing and error="invalid_token" otherwise (RFC 6750)
+*****************************************************************/
+func JWTMiddlewareWithConfig(cfg JWTConfig) gin.HandlerFunc {
+	sources, err := cfg.tokenSources()
+	if err != nil {
+		panic(fmt.Sprintf("talentpitchtools: %v", err))
+	}
+
 	return func(c *gin.Context) {
-		tokenHeader := c.GetHeader("Authorization")
-		if tokenHeader == "" {
-			c.AbortWithStatus(http.StatusUnauthorized)
+		if isPreflightRequest(c) {
+			c.Next()
 			return
 		}
 
-		tokenSplit := strings.Split(tokenHeader, " ")
-		if len(tokenSplit) != 2 {
-			c.AbortWithStatus(http.StatusUnauthorized)
+		tokenString, err := extractToken(c, sources)
+		if err == errTokenMissing {
+			cfg.abort(c, http.StatusUnauthorized, JWTErrorTokenMissing, "Token is required")
+			return
+		}
+		if err != nil {
+			cfg.abort(c, http.StatusUnauthorized, JWTErrorTokenMalformed, err.Error())
 			return
 		}
 
-		tokenString := tokenSplit[1]
-		//token validation
-		token, err := jwt.ParseWithClaims(tokenString, &helpers.CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.AbortWithStatus(http.StatusForbidden)
+		claims, rejection := cfg.validateToken(c, tokenString)
+		if rejection != nil {
+			cfg.abort(c, rejection.status, rejection.code, rejection.message)
 			return
 		}
 		// if token is valid, set user in context
-		claims := token.Claims.(*helpers.CustomClaims)
-
 		c.Set("user", claims)
 
 		c.Next()
 	}
 }
 
+/*****************************************************************
+* Function Name: JWTMiddlewareWithSkip
+* Description: JWTMiddleware that lets the requests matching skipPaths
+* through without authentication (health, metrics, webhooks...)
+* Patterns match exactly, by prefix when ending in a star segment,
+* or as globs (see matchesAnyPath)
+*****************************************************************/
+func JWTMiddlewareWithSkip(jwtSecret string, skipPaths []string) gin.HandlerFunc {
+	jwtMiddleware := JWTMiddleware(jwtSecret)
+	return func(c *gin.Context) {
+		if matchesAnyPath(c.Request.URL.Path, skipPaths) {
+			c.Next()
+			return
+		}
+		jwtMiddleware(c)
+	}
+}
+
+// matchesAnyPath checks the request path against the skip patterns:
+// "/health" matches exactly, "/webhooks/*" matches everything under /webhooks/,
+// and other glob patterns (e.g., "/v*/status") use path.Match
+func matchesAnyPath(requestPath string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "/*") {
+			prefix := strings.TrimSuffix(pattern, "*")
+			if strings.HasPrefix(requestPath, prefix) || requestPath == strings.TrimSuffix(prefix, "/") {
+				return true
+			}
+			continue
+		}
+		if matched, err := path.Match(pattern, requestPath); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SwaggerBasicAuth protects the Swagger docs with a single account
+// See SwaggerBasicAuthMulti for several accounts
 func SwaggerBasicAuth(email, password string) gin.HandlerFunc {
-	return gin.BasicAuth(gin.Accounts{
+	return SwaggerBasicAuthMulti(map[string]string{
 		email: password,
 	})
 }
@@ -145,29 +223,83 @@ func clientIPMiddleware() gin.HandlerFunc {
 	}
 }
 
-func getClientIP(c *gin.Context) string {
-	// Check X-Forwarded-For header first (used by ngrok, Cloudflare, etc.)
-	forwardedFor := c.GetHeader("X-Forwarded-For")
-	if forwardedFor != "" {
-		// X-Forwarded-For can contain multiple IPs: "client, proxy1, proxy2"
-		// The first IP is the original client IP
-		ips := strings.Split(forwardedFor, ",")
-		if len(ips) > 0 {
-			ip := strings.TrimSpace(ips[0])
-			// Validate it's a valid IP address
-			if net.ParseIP(ip) != nil {
-				return ip
+// clientIPMiddlewareWithTrustedProxies is clientIPMiddleware trusting the forwarded
+// headers only from peers in the trusted ranges (see WithTrustedProxyCIDRs)
+func clientIPMiddlewareWithTrustedProxies(trusted []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("client_ip", getClientIPFromTrusted(c, trusted))
+		c.Next()
+	}
+}
+
+/*****************************************************************
+* Function Name: baseURLMiddleware
+* Description: Middleware that reads the URL resolved by the location
+* middleware and stores it in context as a ready-to-use base URL
+* (e.g., "https://api.talentpitch.co", without trailing slash)
+* Must be registered after location.Default(); without the location
+* middleware the URL is built from the request (TLS and Host)
+* Then use: c.GetString("base_url") to build absolute URLs
+*****************************************************************/
+func baseURLMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if url := location.Get(c); url != nil {
+			c.Set("base_url", strings.TrimSuffix(url.Scheme+"://"+url.Host+url.Path, "/"))
+		} else if c.Request.Host != "" {
+			scheme := "http"
+			if c.Request.TLS != nil {
+				scheme = "https"
 			}
+			c.Set("base_url", scheme+"://"+c.Request.Host)
 		}
+		c.Next()
 	}
+}
+
+/*****************************************************************
+* Function Name: RequireJSONMiddleware
+* Description: Middleware that rejects requests with a body (POST, PUT, PATCH)
+* whose Content-Type is not application/json with 415 Unsupported Media Type
+* Requests without a body are allowed through
+*****************************************************************/
+func RequireJSONMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
 
-	// Check X-Real-IP header (alternative header used by some proxies)
-	realIP := c.GetHeader("X-Real-IP")
-	if realIP != "" {
-		ip := strings.TrimSpace(realIP)
-		if net.ParseIP(ip) != nil {
-			return ip
+		if c.Request.ContentLength == 0 {
+			c.Next()
+			return
+		}
+
+		if !isJSONContentType(c.GetHeader("Content-Type")) {
+			abortWithError(c, http.StatusUnsupportedMediaType, "unsupported_media_type", "Content-Type must be application/json")
+			return
 		}
+
+		c.Next()
+	}
+}
+
+// isJSONContentType checks for application/json or a +json media type (e.g., application/problem+json)
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func getClientIP(c *gin.Context) string {
+	// Check X-Forwarded-For header first (used by ngrok, Cloudflare, etc.), then
+	// X-Real-IP (alternative header used by some proxies). IPv6 addresses may be
+	// bracketed and carry a port or zone
+	if ip, ok := forwardedClientIP(c); ok {
+		return ip
 	}
 
 	// Fallback to Gin's ClientIP() method
@@ -175,7 +307,8 @@ func getClientIP(c *gin.Context) string {
 }
 
 // SetupTalentpitchMiddlewares is a convenience function that sets up all middlewares
-func SetupTalentpitchMiddlewares(r *gin.Engine, jwtSecret string, trustedProxies []string) (*gin.Engine, error) {
-	return SetupLocationWithTrustedProxies(r, jwtSecret, trustedProxies)
+// Optional middlewares (e.g., WithCORS) are enabled with opts
+func SetupTalentpitchMiddlewares(r *gin.Engine, jwtSecret string, trustedProxies []string, opts ...SetupOption) (*gin.Engine, error) {
+	return SetupLocationWithTrustedProxies(r, jwtSecret, trustedProxies, opts...)
 }
 
@@ -0,0 +1,159 @@
+package talentpitchtools
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/TalentPitchCode/talentpitch-tools-go/groq"
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultModerationMaxBodyBytes is the largest JSON body the moderation middleware reads
+const DefaultModerationMaxBodyBytes = 1 << 20 // 1 MiB
+
+// ModerationConfig holds configuration for ModerationTagMiddlewareWithConfig
+type ModerationConfig struct {
+	// FieldPath is a dot-separated path into the JSON body (e.g., "message" or "data.text")
+	FieldPath string
+	// MaxBodyBytes caps the body read to find the field (defaults to
+	// DefaultModerationMaxBodyBytes). Larger bodies are rejected with 413
+	MaxBodyBytes int64
+}
+
+/*****************************************************************
+* Function Name: ModerationTagMiddleware
+* Description: Middleware that moderates a JSON body field with Groq
+* and stores the groq.ModerationResult in context without aborting,
+* so the handler decides what to do (shadow/observation deployments)
+* Bodies larger than DefaultModerationMaxBodyBytes are rejected with 413
+* Then use: c.Get("moderation_result") to get the result
+*****************************************************************/
+func ModerationTagMiddleware(client *groq.Client, fieldPath string) gin.HandlerFunc {
+	return ModerationTagMiddlewareWithConfig(client, ModerationConfig{FieldPath: fieldPath})
+}
+
+/*****************************************************************
+* Function Name: ModerationTagMiddlewareWithConfig
+* Description: ModerationTagMiddleware with a configurable body size cap
+* Bodies larger than MaxBodyBytes are rejected with 413 request_too_large
+*****************************************************************/
+func ModerationTagMiddlewareWithConfig(client *groq.Client, cfg ModerationConfig) gin.HandlerFunc {
+	maxBodyBytes := cfg.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = DefaultModerationMaxBodyBytes
+	}
+
+	return func(c *gin.Context) {
+		body, err := readRequestBody(c, maxBodyBytes)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			abortWithError(c, http.StatusRequestEntityTooLarge, "request_too_large", "Request body is too large")
+			return
+		}
+
+		if result, ok := moderateField(c, client, cfg.FieldPath, body); ok {
+			c.Set("moderation_result", result)
+		}
+		c.Next()
+	}
+}
+
+// Moderation response headers set by SetModerationHeaders
+const (
+	ModerationCodeHeader    = "X-Moderation-Code"
+	ModerationWarningHeader = "X-Moderation-Warning"
+)
+
+// SetModerationHeaders reflects the groq.ModerationResult stored in context by the
+// moderation middleware in the response headers, so frontends can react to
+// soft-warnings without changing the JSON body:
+//   - X-Moderation-Code: the error code, if any
+//   - X-Moderation-Warning: "true" when the content was allowed with a warning
+//
+// Call it in the handler before writing the response. Returns false if there is no
+// result in context
+func SetModerationHeaders(c *gin.Context) bool {
+	value, ok := c.Get("moderation_result")
+	if !ok {
+		return false
+	}
+	result, ok := value.(groq.ModerationResult)
+	if !ok {
+		return false
+	}
+
+	if result.ErrorCode != "" {
+		c.Header(ModerationCodeHeader, result.ErrorCode)
+	}
+	if result.Warning {
+		c.Header(ModerationWarningHeader, "true")
+	}
+	return true
+}
+
+// moderateField reads the field from the JSON body and moderates it
+// Returns false if the field is missing, empty or the moderation failed
+func moderateField(c *gin.Context, client *groq.Client, fieldPath string, body []byte) (groq.ModerationResult, bool) {
+	text, ok := readJSONField(body, fieldPath)
+	if !ok || text == "" {
+		return groq.ModerationResult{}, false
+	}
+
+	// A locale stored in context by a locale middleware is passed on to the prompt
+	ctx := c.Request.Context()
+	if locale := c.GetString("locale"); locale != "" {
+		ctx = groq.WithLocale(ctx, locale)
+	}
+
+	result, err := client.CheckMessageContentDetailed(ctx, text)
+	if err != nil {
+		log.Printf("Error moderating field %s%s: %v", fieldPath, logRequestID(c), err)
+		return groq.ModerationResult{}, false
+	}
+
+	return result, true
+}
+
+// readRequestBody reads up to maxBytes of the request body and restores it so
+// handlers can still bind it. Larger bodies return an *http.MaxBytesError
+func readRequestBody(c *gin.Context, maxBytes int64) ([]byte, error) {
+	if c.Request.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+	c.Request.Body = io.NopCloser(bytes.NewBuffer(body))
+	return body, err
+}
+
+// readJSONField reads a string field from the JSON body following a
+// dot-separated path
+func readJSONField(body []byte, fieldPath string) (string, bool) {
+	if len(body) == 0 {
+		return "", false
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "", false
+	}
+
+	for _, key := range strings.Split(fieldPath, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		value, ok = object[key]
+		if !ok {
+			return "", false
+		}
+	}
+
+	text, ok := value.(string)
+	return text, ok
+}
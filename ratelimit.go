@@ -0,0 +1,207 @@
+package talentpitchtools
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultRateLimitRequests = 60
+	defaultRateLimitWindow   = time.Minute
+)
+
+// RateLimitStore keeps the rate limit state, so it can be shared between replicas
+// (e.g., backed by Redis). The default is an in-memory token bucket per key
+type RateLimitStore interface {
+	// Allow consumes one request for key, allowing limit requests per window. When the
+	// request is not allowed, retryAfter is how long until the next one will be
+	Allow(key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// RateLimitConfig holds configuration for RateLimitMiddleware
+type RateLimitConfig struct {
+	// Requests is the number of requests allowed per Window (defaults to 60)
+	Requests int
+	// Window is the rate limit window (defaults to 1 minute)
+	Window time.Duration
+	// Store keeps the rate limit state (defaults to an in-memory store)
+	Store RateLimitStore
+	// AllowedIPs are IPs or CIDR ranges (e.g., "10.0.0.0/8") that bypass the limit
+	AllowedIPs []string
+}
+
+/*****************************************************************
+* Function Name: RateLimitMiddleware
+* Description: Middleware that limits the requests per client IP,
+* responding 429 rate_limited with a Retry-After header when exceeded
+* IPs in AllowedIPs are never limited
+* The key is the IP stored in context by the client IP middleware,
+* falling back to c.ClientIP() (which honors gin's trusted proxies)
+* Configure WithTrustedProxyCIDRs so clients cannot rotate
+* X-Forwarded-For to get a new bucket
+* Store errors fail open: the request is allowed and the error logged
+*****************************************************************/
+func RateLimitMiddleware(cfg RateLimitConfig) gin.HandlerFunc {
+	limit := cfg.Requests
+	if limit <= 0 {
+		limit = defaultRateLimitRequests
+	}
+	window := cfg.Window
+	if window <= 0 {
+		window = defaultRateLimitWindow
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore()
+	}
+	allowed := parseIPRanges(cfg.AllowedIPs)
+
+	return func(c *gin.Context) {
+		ip := rateLimitKey(c)
+
+		if ipInRanges(ip, allowed) {
+			c.Next()
+			return
+		}
+
+		ok, retryAfter, err := store.Allow(ip, limit, window)
+		if err != nil {
+			log.Printf("Error checking rate limit for %s: %v", ip, err)
+			c.Next()
+			return
+		}
+		if !ok {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			abortWithError(c, http.StatusTooManyRequests, "rate_limited", "Too many requests")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// rateLimitKey returns the client IP stored by the client IP middleware, falling back
+// to c.ClientIP()
+func rateLimitKey(c *gin.Context) string {
+	if ip := c.GetString("client_ip"); ip != "" {
+		return ip
+	}
+	return c.ClientIP()
+}
+
+// MemoryRateLimitStore is an in-memory RateLimitStore using a token bucket per key:
+// each key may burst up to limit requests, refilled evenly over the window
+// It is local to the process, so each replica limits independently
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+// tokenBucket is the state of a key in MemoryRateLimitStore
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewMemoryRateLimitStore creates an empty in-memory rate limit store
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow consumes a token of the key's bucket, refilled at limit tokens per window
+func (s *MemoryRateLimitStore) Allow(key string, limit int, window time.Duration) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now, window)
+
+	rate := float64(limit) / window.Seconds()
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit), last: now}
+		s.buckets[key] = bucket
+	} else {
+		bucket.tokens = math.Min(float64(limit), bucket.tokens+now.Sub(bucket.last).Seconds()*rate)
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		retryAfter := time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+		return false, retryAfter, nil
+	}
+	bucket.tokens--
+	return true, 0, nil
+}
+
+// prune drops, at most once per window, the buckets idle for a whole window: they
+// are full again, so forgetting them does not change any decision
+func (s *MemoryRateLimitStore) prune(now time.Time, window time.Duration) {
+	if now.Sub(s.lastPrune) < window {
+		return
+	}
+	s.lastPrune = now
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) >= window {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// parseIPRanges parses IPs and CIDR ranges, skipping (and logging) invalid entries
+func parseIPRanges(entries []string) []*net.IPNet {
+	var ranges []*net.IPNet
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				log.Printf("Skipping invalid IP: %q", entry)
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			ranges = append(ranges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Printf("Skipping invalid CIDR range %q: %v", entry, err)
+			continue
+		}
+		ranges = append(ranges, ipNet)
+	}
+	return ranges
+}
+
+// ipInRanges checks if the IP is in any of the ranges
+func ipInRanges(ip string, ranges []*net.IPNet) bool {
+	if len(ranges) == 0 {
+		return false
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range ranges {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
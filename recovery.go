@@ -0,0 +1,56 @@
+package talentpitchtools
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecoveryConfig holds configuration for RecoveryMiddleware
+type RecoveryConfig struct {
+	// IncludeStack adds the stack trace to the error response. Only enable it outside
+	// production (e.g., gin.Mode() != gin.ReleaseMode); it is always logged
+	IncludeStack bool
+}
+
+/*****************************************************************
+* Function Name: RecoveryMiddleware
+* Description: Middleware that recovers panics in the downstream
+* middlewares and handlers, logs them with the request ID, client IP
+* and stack trace, and responds 500 internal_error with the standard
+* error body instead of Gin's default response
+* If the response was already written, the request is only aborted
+*****************************************************************/
+func RecoveryMiddleware(cfg RecoveryConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				// The handler deliberately aborted the response
+				panic(recovered)
+			}
+
+			stack := string(debug.Stack())
+			log.Printf("Recovered from panic%s (client_ip=%s): %v\n%s", logRequestID(c), c.GetString("client_ip"), recovered, stack)
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+
+			response := newErrorResponse(c, "internal_error", "Internal server error")
+			if cfg.IncludeStack {
+				response.Stack = stack
+			}
+			ErrorResponder(c, http.StatusInternalServerError, response)
+			c.Abort()
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,84 @@
+package talentpitchtools
+
+import (
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header carrying the request correlation ID
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds incoming request IDs, which end up in logs and responses
+const maxRequestIDLength = 128
+
+/*****************************************************************
+* Function Name: RequestIDMiddleware
+* Description: Middleware that gives every request a correlation ID
+* Reuses the incoming X-Request-ID header (if it is at most 128
+* printable ASCII characters) or generates a UUID, stores it in
+* context and sets it on the response header
+* Then use: talentpitchtools.GetRequestID(c) to get the ID
+*****************************************************************/
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// GetRequestID returns the request ID stored by RequestIDMiddleware, falling back to
+// the X-Request-ID header when it passes the same validation. Returns "" if there is
+// none, so unvalidated client input never reaches logs or error responses
+func GetRequestID(c *gin.Context) string {
+	if requestID := c.GetString("request_id"); requestID != "" {
+		return requestID
+	}
+	if requestID := c.GetHeader(RequestIDHeader); isValidRequestID(requestID) {
+		return requestID
+	}
+	return ""
+}
+
+// logRequestID returns " (request_id=...)" to append to log lines, or "" if the
+// request has no ID
+func logRequestID(c *gin.Context) string {
+	requestID := GetRequestID(c)
+	if requestID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (request_id=%s)", requestID)
+}
+
+// isValidRequestID rejects empty, overlong or non-printable IDs, so clients cannot
+// inject arbitrary content into the logs
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random UUID (version 4)
+func newRequestID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(fmt.Sprintf("talentpitchtools: could not generate request ID: %v", err))
+	}
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
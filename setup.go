@@ -0,0 +1,310 @@
+package talentpitchtools
+
+import (
+	"fmt"
+
+	"github.com/gin-contrib/location"
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware names used in MiddlewareOptions.Order
+const (
+	MiddlewareRecovery  = "recovery"
+	MiddlewareRequestID = "request_id"
+	MiddlewareLogger    = "logger"
+	MiddlewareCORS      = "cors"
+	MiddlewareLocation  = "location"
+	MiddlewareBaseURL   = "base_url"
+	MiddlewareClientIP  = "client_ip"
+	MiddlewareRateLimit = "rate_limit"
+	MiddlewareJWT       = "jwt"
+)
+
+// DefaultMiddlewareOrder is the registration order of SetupTalentpitchMiddlewaresWithOptions:
+// recovery outermost so panics anywhere get the standard error response, the request
+// ID before anything logs, CORS before authentication so preflight requests are
+// answered, and rate limiting once the client IP is resolved
+var DefaultMiddlewareOrder = []string{
+	MiddlewareRecovery,
+	MiddlewareRequestID,
+	MiddlewareLogger,
+	MiddlewareCORS,
+	MiddlewareLocation,
+	MiddlewareBaseURL,
+	MiddlewareClientIP,
+	MiddlewareRateLimit,
+	MiddlewareJWT,
+}
+
+// skippableMiddlewares are the middlewares bypassed for MiddlewareOptions.SkipPaths
+var skippableMiddlewares = map[string]bool{
+	MiddlewareLogger:    true,
+	MiddlewareLocation:  true,
+	MiddlewareBaseURL:   true,
+	MiddlewareRateLimit: true,
+	MiddlewareJWT:       true,
+}
+
+// MiddlewareOptions configures SetupTalentpitchMiddlewaresWithOptions. Start from
+// DefaultMiddlewareOptions, which enables what SetupTalentpitchMiddlewares registers
+type MiddlewareOptions struct {
+	// JWTSecret enables the optional JWT middleware (sets the user of valid tokens
+	// without blocking requests). Empty disables it
+	JWTSecret string
+	// JWT configures the optional JWT middleware like JWTMiddlewareWithConfig (key
+	// provider, expected issuers and audience, revocation, user status), taking
+	// precedence over JWTSecret. Rejected tokens are treated as anonymous
+	JWT *JWTConfig
+	// TrustedProxies is passed to gin's SetTrustedProxies
+	TrustedProxies []string
+	// TrustedProxyCIDRs, when not nil, honors X-Forwarded-For/X-Real-IP only from
+	// peers in these ranges (see WithTrustedProxyCIDRs). An empty slice trusts none
+	TrustedProxyCIDRs []string
+
+	// DisableLocation skips the location middleware, for services behind a proxy that
+	// already normalizes scheme and host. base_url is then built from the request itself
+	DisableLocation bool
+	// Location configures the location middleware (e.g., which headers it reads the
+	// scheme and host from). nil means location.DefaultConfig()
+	Location *location.Config
+
+	EnableRecovery bool
+	Recovery       RecoveryConfig
+
+	EnableRequestID bool
+
+	EnableLogger bool
+	Logger       LogConfig
+
+	EnableCORS bool
+	CORS       CORSConfig
+
+	EnableRateLimit bool
+	RateLimit       RateLimitConfig
+
+	// SkipPaths are paths that bypass the logger, location, base URL, rate limit and
+	// JWT middlewares, like load balancer health checks ("/health", "/ready").
+	// Recovery, request ID, CORS and client IP still run. Patterns match like in
+	// JWTMiddlewareWithSkip
+	SkipPaths []string
+
+	// Order is the registration order of the middlewares by name (e.g.,
+	// MiddlewareCORS). It must list every enabled middleware; disabled ones are
+	// skipped. Defaults to DefaultMiddlewareOrder
+	Order []string
+}
+
+// DefaultMiddlewareOptions returns the options of SetupTalentpitchMiddlewares without
+// JWT: recovery, request ID, location (default config), base URL and client IP
+func DefaultMiddlewareOptions() MiddlewareOptions {
+	return MiddlewareOptions{
+		EnableRecovery:  true,
+		EnableRequestID: true,
+	}
+}
+
+// jwtConfig returns the configuration of the optional JWT middleware, if enabled
+func (opts MiddlewareOptions) jwtConfig() (JWTConfig, bool) {
+	if opts.JWT != nil {
+		return *opts.JWT, true
+	}
+	if opts.JWTSecret != "" {
+		return JWTConfig{Secret: opts.JWTSecret}, true
+	}
+	return JWTConfig{}, false
+}
+
+// middlewares builds the enabled middlewares in order
+func (opts MiddlewareOptions) middlewares() ([]gin.HandlerFunc, error) {
+	clientIP := clientIPMiddleware
+	if opts.TrustedProxyCIDRs != nil {
+		ranges := parseIPRanges(opts.TrustedProxyCIDRs)
+		clientIP = func() gin.HandlerFunc { return clientIPMiddlewareWithTrustedProxies(ranges) }
+	}
+
+	// Base URL and client IP are always registered, location unless disabled
+	available := map[string]func() gin.HandlerFunc{
+		MiddlewareBaseURL:  baseURLMiddleware,
+		MiddlewareClientIP: clientIP,
+	}
+	if !opts.DisableLocation {
+		locationConfig := location.DefaultConfig()
+		if opts.Location != nil {
+			locationConfig = *opts.Location
+		}
+		available[MiddlewareLocation] = func() gin.HandlerFunc { return location.New(locationConfig) }
+	}
+	if opts.EnableRecovery {
+		available[MiddlewareRecovery] = func() gin.HandlerFunc { return RecoveryMiddleware(opts.Recovery) }
+	}
+	if opts.EnableRequestID {
+		available[MiddlewareRequestID] = RequestIDMiddleware
+	}
+	if opts.EnableLogger {
+		available[MiddlewareLogger] = func() gin.HandlerFunc { return LoggerMiddleware(opts.Logger) }
+	}
+	if opts.EnableCORS {
+		available[MiddlewareCORS] = func() gin.HandlerFunc { return CORSMiddleware(opts.CORS) }
+	}
+	if opts.EnableRateLimit {
+		available[MiddlewareRateLimit] = func() gin.HandlerFunc { return RateLimitMiddleware(opts.RateLimit) }
+	}
+	if jwtConfig, ok := opts.jwtConfig(); ok {
+		if _, err := jwtConfig.tokenSources(); err != nil {
+			return nil, err
+		}
+		available[MiddlewareJWT] = func() gin.HandlerFunc { return optionalJWTMiddleware(jwtConfig) }
+	}
+
+	order := opts.Order
+	if len(order) == 0 {
+		order = DefaultMiddlewareOrder
+	}
+
+	known := make(map[string]bool, len(DefaultMiddlewareOrder))
+	for _, name := range DefaultMiddlewareOrder {
+		known[name] = true
+	}
+
+	var handlers []gin.HandlerFunc
+	listed := make(map[string]bool, len(order))
+	for _, name := range order {
+		if !known[name] {
+			return nil, fmt.Errorf("unknown middleware %q in Order", name)
+		}
+		if listed[name] {
+			return nil, fmt.Errorf("middleware %q listed twice in Order", name)
+		}
+		listed[name] = true
+		if build, ok := available[name]; ok {
+			handler := build()
+			if skippableMiddlewares[name] && len(opts.SkipPaths) > 0 {
+				handler = skipPathsMiddleware(handler, opts.SkipPaths)
+			}
+			handlers = append(handlers, handler)
+		}
+	}
+	for _, name := range DefaultMiddlewareOrder {
+		if _, enabled := available[name]; enabled && !listed[name] {
+			return nil, fmt.Errorf("middleware %q is enabled but missing from Order", name)
+		}
+	}
+	return handlers, nil
+}
+
+// skipPathsMiddleware runs the handler except for requests matching the skip paths
+func skipPathsMiddleware(handler gin.HandlerFunc, skipPaths []string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if matchesAnyPath(c.Request.URL.Path, skipPaths) {
+			c.Next()
+			return
+		}
+		handler(c)
+	}
+}
+
+// SetupOption enables optional middlewares in SetupTalentpitchMiddlewares
+type SetupOption func(*setupOptions)
+
+// setupOptions holds the optional middlewares enabled by SetupOption
+type setupOptions struct {
+	cors     *CORSConfig
+	logger   *LogConfig
+	recovery RecoveryConfig
+
+	trustedProxyCIDRs []string
+
+	disableLocation bool
+	location        *location.Config
+
+	skipPaths []string
+
+	jwt *JWTConfig
+}
+
+// WithCORS registers CORSMiddleware with the given configuration before the other
+// middlewares, so preflight requests are answered before authentication
+func WithCORS(cfg CORSConfig) SetupOption {
+	return func(opts *setupOptions) {
+		opts.cors = &cfg
+	}
+}
+
+// WithLogger registers LoggerMiddleware with the given configuration right after the
+// request ID middleware, so every request is timed and logged with its ID
+func WithLogger(cfg LogConfig) SetupOption {
+	return func(opts *setupOptions) {
+		opts.logger = &cfg
+	}
+}
+
+// WithRecovery configures the RecoveryMiddleware registered by
+// SetupTalentpitchMiddlewares (e.g., to include stack traces outside production)
+func WithRecovery(cfg RecoveryConfig) SetupOption {
+	return func(opts *setupOptions) {
+		opts.recovery = cfg
+	}
+}
+
+// WithJWTConfig validates the tokens of the optional JWT middleware with the given
+// configuration instead of only the jwtSecret (see MiddlewareOptions.JWT)
+func WithJWTConfig(cfg JWTConfig) SetupOption {
+	return func(opts *setupOptions) {
+		opts.jwt = &cfg
+	}
+}
+
+// WithoutLocation skips the location middleware, for services behind a proxy that
+// already normalizes scheme and host. Trusted proxies and client IP are still set up
+func WithoutLocation() SetupOption {
+	return func(opts *setupOptions) {
+		opts.disableLocation = true
+	}
+}
+
+// WithLocationConfig configures the location middleware, e.g., to read the host from
+// a header set by our own proxy instead of X-Forwarded-For
+func WithLocationConfig(cfg location.Config) SetupOption {
+	return func(opts *setupOptions) {
+		opts.location = &cfg
+	}
+}
+
+// WithSkipPaths makes the paths (e.g., "/health", "/ready") bypass the logger,
+// location, base URL, rate limit and JWT middlewares
+func WithSkipPaths(paths ...string) SetupOption {
+	return func(opts *setupOptions) {
+		opts.skipPaths = append(opts.skipPaths, paths...)
+	}
+}
+
+// newSetupOptions applies the options
+func newSetupOptions(opts []SetupOption) setupOptions {
+	var options setupOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// apply sets the middlewares enabled by the SetupOptions in the MiddlewareOptions
+func (options setupOptions) apply(opts *MiddlewareOptions) {
+	opts.Recovery = options.recovery
+	if options.logger != nil {
+		opts.EnableLogger = true
+		opts.Logger = *options.logger
+	}
+	if options.cors != nil {
+		opts.EnableCORS = true
+		opts.CORS = *options.cors
+	}
+	if options.trustedProxyCIDRs != nil {
+		opts.TrustedProxyCIDRs = options.trustedProxyCIDRs
+	}
+	opts.DisableLocation = options.disableLocation
+	opts.Location = options.location
+	opts.SkipPaths = options.skipPaths
+	if options.jwt != nil {
+		opts.JWT = options.jwt
+	}
+}
@@ -2,17 +2,69 @@ package validators
 
 import (
 	"context"
+	"errors"
 	"log"
+	"time"
 
 	"github.com/TalentPitchCode/talentpitch-tools-go/groq"
 	"github.com/go-playground/validator/v10"
 )
 
+// DefaultValidationTimeout bounds each "acceptable" validation unless WithTimeout
+// is used, so a hung Groq call never blocks the request goroutine indefinitely
+const DefaultValidationTimeout = 10 * time.Second
+
+// AcceptableOption configures the "acceptable" validator
+type AcceptableOption func(*acceptableConfig)
+
+type acceptableConfig struct {
+	timeout  time.Duration
+	failOpen bool
+}
+
+// WithTimeout bounds each validation with a context timeout so a slow Groq call
+// cannot hang the request (defaults to DefaultValidationTimeout; 0 disables it and
+// relies on the context and the client RequestTimeout). On timeout the message is
+// rejected (fail closed)
+func WithTimeout(timeout time.Duration) AcceptableOption {
+	return func(cfg *acceptableConfig) {
+		cfg.timeout = timeout
+	}
+}
+
+// WithFailOpen accepts messages when the Groq check fails (e.g., during an outage)
+// instead of rejecting them. The message is still checked against the blocked terms,
+// patterns and domains, so the worst content is rejected without the AI
+// By default the validator fails closed
+func WithFailOpen() AcceptableOption {
+	return func(cfg *acceptableConfig) {
+		cfg.failOpen = true
+	}
+}
+
 // AcceptableMessageValidator creates a validator function for the "acceptable" tag
-// that checks if a message is acceptable using the Groq client
+// that checks if a message is acceptable using the moderator (e.g., a *groq.Client
+// or a groq.FallbackModerator)
 // The validator returns true if the message is NOT malicious (i.e., acceptable)
-func AcceptableMessageValidator(groqClient *groq.Client) validator.Func {
+// It uses context.Background() bounded by DefaultValidationTimeout; prefer
+// AcceptableMessageValidatorCtx so a client disconnect cancels the Groq call
+func AcceptableMessageValidator(moderator groq.Moderator, opts ...AcceptableOption) validator.Func {
+	validate := AcceptableMessageValidatorCtx(moderator, opts...)
 	return func(fl validator.FieldLevel) bool {
+		return validate(context.Background(), fl)
+	}
+}
+
+// AcceptableMessageValidatorCtx is like AcceptableMessageValidator but uses the
+// context passed to validate.StructCtx (e.g., c.Request.Context()), so the Groq call
+// is cancelled when the HTTP client disconnects
+func AcceptableMessageValidatorCtx(moderator groq.Moderator, opts ...AcceptableOption) validator.FuncCtx {
+	cfg := acceptableConfig{timeout: DefaultValidationTimeout}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	return func(ctx context.Context, fl validator.FieldLevel) bool {
 		msg := fl.Field().String()
 		
 		// If message is empty and field is optional (omitempty), skip validation
@@ -20,10 +72,28 @@ func AcceptableMessageValidator(groqClient *groq.Client) validator.Func {
 			return true
 		}
 
-		ctx := context.Background()
-		isMalicious, _, _, err := groqClient.FilterMessageWithAI(ctx, msg)
+		if cfg.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+			defer cancel()
+		}
+
+		isMalicious, _, _, err := moderator.CheckMessageContent(ctx, msg)
 		if err != nil {
-			log.Printf("Error validating message with Groq: %v", err)
+			if errors.Is(err, context.DeadlineExceeded) {
+				log.Printf("Timed out validating message with Groq after %s: %v", cfg.timeout, err)
+			} else if errors.Is(err, context.Canceled) {
+				log.Printf("Validation cancelled, client disconnected: %v", err)
+			} else {
+				log.Printf("Error validating message with Groq: %v", err)
+			}
+			if cfg.failOpen {
+				// Fall back to the local checks only, if the moderator has them
+				if local, ok := moderator.(groq.LocalModerator); ok {
+					return !local.CheckMessageContentLocal(msg).IsMalicious
+				}
+				return true
+			}
 			// On error, reject the message (fail closed for security)
 			return false
 		}
@@ -34,8 +104,9 @@ func AcceptableMessageValidator(groqClient *groq.Client) validator.Func {
 }
 
 // RegisterAcceptableValidator is a convenience function that registers the "acceptable"
-// validator tag with the provided validator instance and Groq client
-func RegisterAcceptableValidator(validate *validator.Validate, groqClient *groq.Client) error {
-	return validate.RegisterValidation("acceptable", AcceptableMessageValidator(groqClient))
+// validator tag with the provided validator instance and moderator
+// Options such as WithTimeout and WithFailOpen are passed through to AcceptableMessageValidatorCtx
+// Validate with validate.StructCtx(c.Request.Context(), req) to plumb the request context
+func RegisterAcceptableValidator(validate *validator.Validate, moderator groq.Moderator, opts ...AcceptableOption) error {
+	return validate.RegisterValidationCtx("acceptable", AcceptableMessageValidatorCtx(moderator, opts...))
 }
-
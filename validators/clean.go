@@ -0,0 +1,43 @@
+package validators
+
+import (
+	"log"
+
+	"github.com/TalentPitchCode/talentpitch-tools-go/groq"
+	"github.com/go-playground/validator/v10"
+)
+
+// CleanMessageValidator creates a validator function for the "clean" tag that rejects
+// fields containing any of the blocked terms. It is a local, synchronous check with
+// no AI call, meant for low-risk fields (use "acceptable" for high-risk ones)
+func CleanMessageValidator(blockedTerms []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		found, term := groq.ContainsBlockedTerm(fl.Field().String(), blockedTerms)
+		if found {
+			log.Printf("Field contains blocked term: %s", term)
+		}
+		return !found
+	}
+}
+
+// CleanMessageValidatorFromClient is like CleanMessageValidator using the client's
+// blocked terms, so reloads, allowed terms and NormalizeEvasion apply. Groq is never called
+func CleanMessageValidatorFromClient(client *groq.Client) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		found, term := client.ContainsBlockedTerm(fl.Field().String())
+		if found {
+			log.Printf("Field contains blocked term: %s", term)
+		}
+		return !found
+	}
+}
+
+// RegisterCleanValidator registers the "clean" validator tag backed by the client's blocked terms
+func RegisterCleanValidator(validate *validator.Validate, client *groq.Client) error {
+	return validate.RegisterValidation("clean", CleanMessageValidatorFromClient(client))
+}
+
+// RegisterCleanValidatorWithTerms registers the "clean" validator tag backed by a fixed terms list
+func RegisterCleanValidatorWithTerms(validate *validator.Validate, blockedTerms []string) error {
+	return validate.RegisterValidation("clean", CleanMessageValidator(blockedTerms))
+}
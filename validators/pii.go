@@ -0,0 +1,111 @@
+package validators
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/TalentPitchCode/talentpitch-tools-go/groq"
+	"github.com/go-playground/validator/v10"
+)
+
+// PIIPattern detects one kind of personal data in free text
+// Regexp finds the candidates and Validate (optional) confirms each match, e.g.,
+// a checksum, so projects can add country-specific ID formats
+type PIIPattern struct {
+	Name     string
+	Regexp   *regexp.Regexp
+	Validate func(match string) bool
+}
+
+var (
+	creditCardRegexp = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	ssnRegexp        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	emailRegexp      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// phoneRegexp is shared with groq.ContainsContactInfo so both flag the same numbers
+	phoneRegexp = groq.PhoneNumberPattern()
+)
+
+// DefaultPIIPatterns returns the patterns checked by the "no_pii" validator:
+// credit cards (Luhn checked), SSN-like IDs, emails and phone numbers
+func DefaultPIIPatterns() []PIIPattern {
+	return []PIIPattern{
+		{Name: "credit_card", Regexp: creditCardRegexp, Validate: isCreditCardNumber},
+		{Name: "ssn", Regexp: ssnRegexp, Validate: isSSN},
+		{Name: "email", Regexp: emailRegexp},
+		{Name: "phone", Regexp: phoneRegexp, Validate: groq.IsPhoneNumber},
+	}
+}
+
+// FindPII returns the name of the first pattern found in text, or "" if there is no PII
+func FindPII(text string, patterns []PIIPattern) string {
+	for _, pattern := range patterns {
+		if pattern.Regexp == nil {
+			continue
+		}
+		for _, match := range pattern.Regexp.FindAllString(text, -1) {
+			if pattern.Validate == nil || pattern.Validate(match) {
+				return pattern.Name
+			}
+		}
+	}
+	return ""
+}
+
+// NoPIIValidator creates a validator function for the "no_pii" tag that rejects
+// fields containing personal data. It is pure pattern matching (no Groq client)
+// extra patterns are checked in addition to DefaultPIIPatterns
+func NoPIIValidator(extra ...PIIPattern) validator.Func {
+	patterns := append(DefaultPIIPatterns(), extra...)
+	return func(fl validator.FieldLevel) bool {
+		return FindPII(fl.Field().String(), patterns) == ""
+	}
+}
+
+// RegisterNoPIIValidator registers the "no_pii" validator tag with the provided
+// validator instance, checking DefaultPIIPatterns plus the extra patterns
+func RegisterNoPIIValidator(validate *validator.Validate, extra ...PIIPattern) error {
+	return validate.RegisterValidation("no_pii", NoPIIValidator(extra...))
+}
+
+// digitsOnly strips the separators of a matched number
+func digitsOnly(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// isCreditCardNumber checks the length and the Luhn checksum, so ordinary long
+// numbers (order IDs, timestamps) are not flagged
+func isCreditCardNumber(match string) bool {
+	digits := digitsOnly(match)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// isSSN discards the area, group and serial numbers never assigned
+func isSSN(match string) bool {
+	parts := strings.Split(match, "-")
+	area, group, serial := parts[0], parts[1], parts[2]
+	if area == "000" || area == "666" || area[0] == '9' {
+		return false
+	}
+	return group != "00" && serial != "0000"
+}
@@ -0,0 +1,62 @@
+package validators
+
+import (
+	"testing"
+
+	"github.com/TalentPitchCode/talentpitch-tools-go/groq"
+)
+
+func TestFindPIIPhone(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"international with spaces", "Call me at +57 300 123 4567", "phone"},
+		{"international compact", "WhatsApp +573001234567", "phone"},
+		{"international with area code", "Office: +1 (555) 123-4567", "phone"},
+		{"grouped mobile", "mi número es 300 123 4567", "phone"},
+		{"grouped with parentheses", "(601) 555-1234", "phone"},
+		{"grouped with hyphens", "555-123-4567", "phone"},
+		{"unformatted ten digits", "3001234567", "phone"},
+		{"salary range", "Salario: 1.500.000 - 2.000.000", ""},
+		{"salary without spaces", "1.500.000-2.000.000 COP", ""},
+		{"date and time", "Entrevista el 2024-01-15 10:30", ""},
+		{"iso timestamp", "2024-01-15T10:30:00Z", ""},
+		{"order id", "Order 123456789012 shipped", ""},
+		{"short code", "Send STOP to 85555", ""},
+		{"plain text", "Looking for a backend developer", ""},
+	}
+
+	patterns := []PIIPattern{{Name: "phone", Regexp: phoneRegexp, Validate: groq.IsPhoneNumber}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindPII(tt.text, patterns); got != tt.want {
+				t.Errorf("FindPII(%q) = %q; want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPIIDefaultPatterns(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"credit card", "Card 4111 1111 1111 1111", "credit_card"},
+		{"ssn", "SSN 123-45-6789", "ssn"},
+		{"email", "Write to jane@example.com", "email"},
+		{"phone", "+57 300 123 4567", "phone"},
+		{"salary range", "Salario: 1.500.000 - 2.000.000", ""},
+		{"date and time", "2024-01-15 10:30", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindPII(tt.text, DefaultPIIPatterns()); got != tt.want {
+				t.Errorf("FindPII(%q) = %q; want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}